@@ -0,0 +1,253 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+const (
+	WorkflowRunsIntent = "workflow_runs_intent"
+	RerunIntent        = "rerun_failed_jobs_intent"
+
+	pendingRerun = "rerun_failed_jobs"
+
+	workflowRunsPerPage = 30 // Enough to find the latest run of each workflow
+	maxWorkflows        = 5
+)
+
+// go-github doesn't cover the Actions API yet, so these decode just what we read.
+
+type WorkflowRuns struct {
+	WorkflowRuns []*WorkflowRun `json:"workflow_runs"`
+}
+
+type WorkflowRun struct {
+	ID           int64      `json:"id"`
+	Name         string     `json:"name"`
+	WorkflowID   int64      `json:"workflow_id"`
+	Status       string     `json:"status"`     // queued, in_progress or completed
+	Conclusion   string     `json:"conclusion"` // Set once completed, like success or failure
+	CreatedAt    time.Time  `json:"created_at"`
+	RunStartedAt *time.Time `json:"run_started_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+	HTMLURL      string     `json:"html_url"`
+}
+
+type WorkflowJobs struct {
+	Jobs []*WorkflowJob `json:"jobs"`
+}
+
+type WorkflowJob struct {
+	Name       string `json:"name"`
+	Conclusion string `json:"conclusion"`
+}
+
+// Failed runs that "re-run the failed jobs" acts on
+type RerunTarget struct {
+	Repo      string   `json:"repo"` // Full name, like "owner/repo"
+	RunIDs    []int64  `json:"runIds"`
+	Workflows []string `json:"workflows"`
+}
+
+// How each conclusion is said after the workflow name
+var conclusionPhrases = map[string]string{
+	"success":         "passed",
+	"failure":         "failed",
+	"timed_out":       "timed out",
+	"startup_failure": "failed to start",
+	"cancelled":       "was cancelled",
+	"skipped":         "was skipped",
+	"action_required": "is waiting for approval",
+	"neutral":         "finished",
+}
+
+func (run *WorkflowRun) failed() bool {
+	switch run.Conclusion {
+	case "failure", "timed_out", "startup_failure":
+		return true
+	}
+	return false
+}
+
+func (run *WorkflowRun) startedAt() time.Time {
+	if run.RunStartedAt != nil {
+		return *run.RunStartedAt
+	}
+	return run.CreatedAt
+}
+
+// The newest run of each workflow, keeping the API's newest first order.
+func latestRuns(runs []*WorkflowRun) []*WorkflowRun {
+	seen := map[int64]bool{}
+	var latest []*WorkflowRun
+	for _, run := range runs {
+		if !seen[run.WorkflowID] && len(latest) < maxWorkflows {
+			seen[run.WorkflowID] = true
+			latest = append(latest, run)
+		}
+	}
+	return latest
+}
+
+// Say a duration roughly, like "45 seconds", "4 minutes" or "1 hour and 5 minutes".
+func spokenDuration(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return pluralize(int(d/time.Second), "second", "seconds")
+	case d < time.Hour:
+		return pluralize(int(d/time.Minute), "minute", "minutes")
+	}
+
+	hours := pluralize(int(d/time.Hour), "hour", "hours")
+	if minutes := int(d % time.Hour / time.Minute); minutes > 0 {
+		return hours + " and " + pluralize(minutes, "minute", "minutes")
+	}
+	return hours
+}
+
+// Describe a run, like "CI failed after 4 minutes, 2 hours ago. The failing jobs were test and lint."
+func describeWorkflowRun(run *WorkflowRun, failingJobs []string, now time.Time, loc *time.Location) string {
+	if run.Status != "completed" {
+		return fmt.Sprintf("%s is still running, it started %s.", run.Name, relativeTime(run.startedAt(), now, loc))
+	}
+
+	phrase, ok := conclusionPhrases[run.Conclusion]
+	if !ok {
+		phrase = "finished"
+	}
+	description := fmt.Sprintf("%s %s after %s, %s.", run.Name, phrase,
+		spokenDuration(run.UpdatedAt.Sub(run.startedAt())), relativeTime(run.UpdatedAt, now, loc))
+
+	switch len(failingJobs) {
+	case 0:
+	case 1:
+		description += fmt.Sprintf(" The failing job was %s.", failingJobs[0])
+	default:
+		description += fmt.Sprintf(" The failing jobs were %s.", joinWords(failingJobs))
+	}
+	return description
+}
+
+func failingJobNames(jobs []*WorkflowJob) []string {
+	var names []string
+	for _, job := range jobs {
+		if job.Conclusion == "failure" || job.Conclusion == "timed_out" {
+			names = append(names, job.Name)
+		}
+	}
+	return names
+}
+
+// Report the latest run of each workflow on a branch, the default branch if none is given.
+func getWorkflowRuns(ctx context.Context, accessToken, spokenRepo, branch string) (FulfillmentBuilder, error) {
+	if spokenRepo == "" {
+		reply := "Which repo? Try asking did the main branch of dailygithub pass."
+		return &SpokenResponse{reply, reply, nil}, nil
+	}
+
+	client := createGithubClient(ctx, accessToken)
+	fullName, err := resolveRepo(ctx, client, spokenRepo)
+	if err != nil {
+		return nil, err
+	} else if fullName == "" {
+		reply := fmt.Sprintf("I couldn't find a repo called %s.", spokenRepo)
+		return &SpokenResponse{reply, reply, nil}, nil
+	}
+
+	owner, name, _ := parseRepoRef(fullName)
+	if branch == "" {
+		repo, _, err := client.Repositories.Get(ctx, owner, name)
+		if err != nil {
+			return nil, err
+		}
+		branch = repo.GetDefaultBranch()
+	}
+
+	var runs WorkflowRuns
+	path := fmt.Sprintf("repos/%s/%s/actions/runs?branch=%s&per_page=%d", owner, name, url.QueryEscape(branch), workflowRunsPerPage)
+	if err := getAPIURL(ctx, client, path, &runs); err != nil {
+		return nil, err
+	}
+
+	latest := latestRuns(runs.WorkflowRuns)
+	if len(latest) == 0 {
+		reply := fmt.Sprintf("There are no workflow runs on %s in %s.", branch, fullName)
+		return &SpokenResponse{reply, reply, nil}, nil
+	}
+
+	loc := preferencesFromContext(ctx).location()
+	now := time.Now()
+	header := fmt.Sprintf("Here's the latest on %s in %s.", branch, fullName)
+	text, speech := header, "<p>"+header+"</p>"
+	target := &RerunTarget{Repo: fullName}
+	for _, run := range latest {
+		var failing []string
+		if run.failed() {
+			var jobs WorkflowJobs
+			jobsURL := fmt.Sprintf("repos/%s/%s/actions/runs/%d/jobs?filter=latest", owner, name, run.ID)
+			if err := getAPIURL(ctx, client, jobsURL, &jobs); err != nil {
+				debugf(ctx, "Failed to get jobs for run %d in %s: %v", run.ID, fullName, err)
+			}
+			failing = failingJobNames(jobs.Jobs)
+			target.RunIDs = append(target.RunIDs, run.ID)
+			target.Workflows = append(target.Workflows, run.Name)
+		}
+
+		description := describeWorkflowRun(run, failing, now, loc)
+		text += "\n" + description + "\n" + run.HTMLURL
+		speech += "<p>" + description + "</p>"
+	}
+
+	if len(target.RunIDs) == 0 {
+		return &SpokenResponse{text, speech, nil}, nil
+	}
+	hint := "You can say re-run the failed jobs."
+	return &SpokenResponse{text + "\n\n" + hint, speech + "<p>" + hint + "</p>", &SessionState{Rerun: target}}, nil
+}
+
+// Re-running is confirmed first, so just ask the question here.
+func confirmRerun(session *SessionState) FulfillmentBuilder {
+	if session.Rerun == nil || len(session.Rerun.RunIDs) == 0 {
+		reply := "There's nothing to re-run. Ask how a repo's builds are doing first."
+		return &SpokenResponse{reply, reply, nil}
+	}
+
+	state := *session
+	state.PendingAction = pendingRerun
+	question := fmt.Sprintf("Re-run the failed jobs in %s on %s?", joinWords(session.Rerun.Workflows), session.Rerun.Repo)
+	return &SpokenResponse{question, question, &state}
+}
+
+func rerunFailedJobs(ctx context.Context, accessToken string, session *SessionState) (FulfillmentBuilder, error) {
+	target := session.Rerun
+	if target == nil {
+		return nil, errors.New("no workflow runs to re-run")
+	}
+	owner, name, ok := parseRepoRef(target.Repo)
+	if !ok {
+		return nil, fmt.Errorf("bad repository reference %q", target.Repo)
+	}
+
+	client := createGithubClient(ctx, accessToken)
+	for _, id := range target.RunIDs {
+		req, err := client.NewRequest("POST", fmt.Sprintf("repos/%s/%s/actions/runs/%d/rerun-failed-jobs", owner, name, id), nil)
+		if err != nil {
+			return nil, err
+		}
+
+		_, err = client.Do(ctx, req, nil)
+		if hasStatus(err, http.StatusForbidden) || hasStatus(err, http.StatusNotFound) {
+			reply := fmt.Sprintf("Sorry, you don't have permission to re-run jobs in %s.", target.Repo)
+			return &SpokenResponse{reply, reply, nil}, nil
+		} else if err != nil {
+			return nil, err
+		}
+	}
+
+	done := fmt.Sprintf("Okay, the failed jobs in %s are running again.", joinWords(target.Workflows))
+	return &SpokenResponse{done, done, nil}, nil
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_spokenDuration(t *testing.T) {
+	tests := []struct {
+		name string
+		d    time.Duration
+		want string
+	}{
+		{"Seconds", 45 * time.Second, "45 seconds"},
+		{"Minute", 90 * time.Second, "1 minute"},
+		{"Minutes", 12 * time.Minute, "12 minutes"},
+		{"Hour", time.Hour, "1 hour"},
+		{"Hours and minutes", 2*time.Hour + 5*time.Minute, "2 hours and 5 minutes"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := spokenDuration(tt.d); got != tt.want {
+				t.Errorf("spokenDuration() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_latestRuns(t *testing.T) {
+	runs := []*WorkflowRun{{ID: 5, WorkflowID: 1}, {ID: 4, WorkflowID: 2}, {ID: 3, WorkflowID: 1}, {ID: 2, WorkflowID: 3}}
+	var ids []int64
+	for _, run := range latestRuns(runs) {
+		ids = append(ids, run.ID)
+	}
+	if len(ids) != 3 || ids[0] != 5 || ids[1] != 4 || ids[2] != 2 {
+		t.Errorf("latestRuns() = %v, want [5 4 2]", ids)
+	}
+}
+
+func Test_describeWorkflowRun(t *testing.T) {
+	now := time.Date(2018, 3, 10, 12, 0, 0, 0, time.UTC)
+	started := now.Add(-2*time.Hour - 4*time.Minute)
+	run := func(status, conclusion string) *WorkflowRun {
+		return &WorkflowRun{Name: "CI", Status: status, Conclusion: conclusion, RunStartedAt: &started, UpdatedAt: now.Add(-2 * time.Hour)}
+	}
+	tests := []struct {
+		name    string
+		run     *WorkflowRun
+		failing []string
+		want    string
+	}{
+		{"Passed", run("completed", "success"), nil, "CI passed after 4 minutes, 2 hours ago."},
+		{"Failed job", run("completed", "failure"), []string{"test"}, "CI failed after 4 minutes, 2 hours ago. The failing job was test."},
+		{"Failed jobs", run("completed", "failure"), []string{"test", "lint"}, "CI failed after 4 minutes, 2 hours ago. The failing jobs were test and lint."},
+		{"Running", run("in_progress", ""), nil, "CI is still running, it started 2 hours ago."},
+		{"Unknown conclusion", run("completed", "stale"), nil, "CI finished after 4 minutes, 2 hours ago."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := describeWorkflowRun(tt.run, tt.failing, now, time.UTC); got != tt.want {
+				t.Errorf("describeWorkflowRun() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+const (
+	ActivityIntent = "activity_intent"
+
+	calendarDateFormat = "2006-01-02"
+)
+
+// The calendar covers the last year by default, which is as long as a streak can be counted.
+const activityQuery = `query($weekStart: DateTime!) {
+  viewer {
+    calendar: contributionsCollection {
+      contributionCalendar {
+        weeks { contributionDays { date contributionCount } }
+      }
+    }
+    week: contributionsCollection(from: $weekStart) {
+      totalCommitContributions
+      totalPullRequestContributions
+      totalPullRequestReviewContributions
+      commitContributionsByRepository(maxRepositories: 1) {
+        repository { nameWithOwner }
+        contributions { totalCount }
+      }
+    }
+  }
+}`
+
+type contributionDay struct {
+	Date              string `json:"date"` // Like "2018-03-10"
+	ContributionCount int    `json:"contributionCount"`
+}
+
+type activityData struct {
+	Viewer struct {
+		Calendar struct {
+			ContributionCalendar struct {
+				Weeks []struct {
+					ContributionDays []contributionDay `json:"contributionDays"`
+				} `json:"weeks"`
+			} `json:"contributionCalendar"`
+		} `json:"calendar"`
+		Week struct {
+			TotalCommitContributions            int `json:"totalCommitContributions"`
+			TotalPullRequestContributions       int `json:"totalPullRequestContributions"`
+			TotalPullRequestReviewContributions int `json:"totalPullRequestReviewContributions"`
+			CommitContributionsByRepository     []struct {
+				Repository struct {
+					NameWithOwner string `json:"nameWithOwner"`
+				} `json:"repository"`
+				Contributions struct {
+					TotalCount int `json:"totalCount"`
+				} `json:"contributions"`
+			} `json:"commitContributionsByRepository"`
+		} `json:"week"`
+	} `json:"viewer"`
+}
+
+type ActivitySummary struct {
+	today        int
+	streak       int // Days in a row with contributions, up to today or yesterday
+	commits      int // This week
+	pullRequests int
+	reviews      int
+	topRepo      string // Most commits this week
+	topRepoCount int
+}
+
+// Count the days in a row with contributions that end today. A streak that ended yesterday
+// still counts, since there's time left today to keep it going.
+func currentStreak(days []contributionDay, today string) int {
+	i := len(days) - 1
+	for i >= 0 && days[i].Date > today {
+		i-- // The calendar can run ahead of the user's timezone
+	}
+	if i >= 0 && days[i].Date == today && days[i].ContributionCount == 0 {
+		i--
+	}
+
+	streak := 0
+	for ; i >= 0 && days[i].ContributionCount > 0; i-- {
+		streak++
+	}
+	return streak
+}
+
+func contributionsOn(days []contributionDay, date string) int {
+	for _, day := range days {
+		if day.Date == date {
+			return day.ContributionCount
+		}
+	}
+	return 0
+}
+
+// Monday at midnight, in loc.
+func startOfWeek(now time.Time, loc *time.Location) time.Time {
+	now = now.In(loc)
+	daysSinceMonday := (int(now.Weekday()) + 6) % 7
+	return time.Date(now.Year(), now.Month(), now.Day()-daysSinceMonday, 0, 0, 0, 0, loc)
+}
+
+func getActivity(ctx context.Context, accessToken string) (FulfillmentBuilder, error) {
+	loc := preferencesFromContext(ctx).location()
+	now := time.Now()
+
+	var data activityData
+	variables := map[string]interface{}{"weekStart": startOfWeek(now, loc).Format(time.RFC3339)}
+	if err := createGraphQLClient(ctx, accessToken).query(ctx, activityQuery, variables, &data); err != nil {
+		return nil, err
+	}
+
+	var days []contributionDay
+	for _, week := range data.Viewer.Calendar.ContributionCalendar.Weeks {
+		days = append(days, week.ContributionDays...)
+	}
+
+	today := now.In(loc).Format(calendarDateFormat)
+	week := data.Viewer.Week
+	summary := &ActivitySummary{
+		today:        contributionsOn(days, today),
+		streak:       currentStreak(days, today),
+		commits:      week.TotalCommitContributions,
+		pullRequests: week.TotalPullRequestContributions,
+		reviews:      week.TotalPullRequestReviewContributions,
+	}
+	if repos := week.CommitContributionsByRepository; len(repos) > 0 {
+		summary.topRepo = repos[0].Repository.NameWithOwner
+		summary.topRepoCount = repos[0].Contributions.TotalCount
+	}
+	return summary, nil
+}
+
+func (sum *ActivitySummary) describe() string {
+	description := "You haven't contributed yet today"
+	if sum.today > 0 {
+		description = fmt.Sprintf("You've made %s today", pluralize(sum.today, "contribution", "contributions"))
+	}
+	switch {
+	case sum.streak > 0 && sum.today > 0:
+		description += fmt.Sprintf(", and you're on a %d day streak", sum.streak)
+	case sum.streak > 0:
+		description += fmt.Sprintf(", but you're on a %d day streak", sum.streak)
+	}
+	description += "."
+
+	if sum.commits == 0 && sum.pullRequests == 0 && sum.reviews == 0 {
+		return description + " You haven't made any commits, pull requests or reviews this week."
+	}
+
+	description += fmt.Sprintf(" This week you've made %s, opened %s and reviewed %s.",
+		pluralize(sum.commits, "commit", "commits"),
+		pluralize(sum.pullRequests, "pull request", "pull requests"),
+		pluralize(sum.reviews, "pull request", "pull requests"))
+	if sum.topRepo != "" {
+		description += fmt.Sprintf(" Your most active repo is %s, with %s.", sum.topRepo, pluralize(sum.topRepoCount, "commit", "commits"))
+	}
+	return description
+}
+
+func (sum *ActivitySummary) buildFulfillment(ctx context.Context) *FulfillmentResp {
+	description := sum.describe()
+	debugf(ctx, "Built fulfillment with string: %s", description)
+	return &FulfillmentResp{"<speak>" + description + "</speak>", description}
+}
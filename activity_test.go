@@ -0,0 +1,80 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_currentStreak(t *testing.T) {
+	days := func(counts ...int) []contributionDay {
+		var result []contributionDay
+		start := time.Date(2018, 3, 1, 0, 0, 0, 0, time.UTC)
+		for i, count := range counts {
+			result = append(result, contributionDay{start.AddDate(0, 0, i).Format(calendarDateFormat), count})
+		}
+		return result
+	}
+	tests := []struct {
+		name  string
+		days  []contributionDay
+		today string
+		want  int
+	}{
+		{"Through today", days(0, 2, 1, 3), "2018-03-04", 3},
+		{"Not yet today", days(0, 2, 1, 0), "2018-03-04", 2},
+		{"Broken", days(1, 0, 0, 0), "2018-03-04", 0},
+		{"Calendar ahead", days(0, 1, 1, 0, 0), "2018-03-04", 2},
+		{"Every day", days(1, 1, 1), "2018-03-03", 3},
+		{"Empty", nil, "2018-03-03", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := currentStreak(tt.days, tt.today); got != tt.want {
+				t.Errorf("currentStreak() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_startOfWeek(t *testing.T) {
+	denver, _ := time.LoadLocation("America/Denver")
+	tests := []struct {
+		name string
+		now  time.Time
+		loc  *time.Location
+		want time.Time
+	}{
+		{"Saturday", time.Date(2018, 3, 10, 12, 0, 0, 0, time.UTC), time.UTC, time.Date(2018, 3, 5, 0, 0, 0, 0, time.UTC)},
+		{"Monday", time.Date(2018, 3, 5, 1, 0, 0, 0, time.UTC), time.UTC, time.Date(2018, 3, 5, 0, 0, 0, 0, time.UTC)},
+		{"Sunday", time.Date(2018, 3, 11, 23, 0, 0, 0, time.UTC), time.UTC, time.Date(2018, 3, 5, 0, 0, 0, 0, time.UTC)},
+		{"Still Sunday in Denver", time.Date(2018, 3, 12, 3, 0, 0, 0, time.UTC), denver, time.Date(2018, 3, 5, 0, 0, 0, 0, denver)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := startOfWeek(tt.now, tt.loc); !got.Equal(tt.want) {
+				t.Errorf("startOfWeek() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_ActivitySummary_describe(t *testing.T) {
+	tests := []struct {
+		name string
+		sum  ActivitySummary
+		want string
+	}{
+		{"Quiet", ActivitySummary{}, "You haven't contributed yet today. You haven't made any commits, pull requests or reviews this week."},
+		{"Busy", ActivitySummary{3, 5, 12, 2, 1, "ephraimkunz/DailyGithub", 9},
+			"You've made 3 contributions today, and you're on a 5 day streak. This week you've made 12 commits, opened 2 pull requests and reviewed 1 pull request. Your most active repo is ephraimkunz/DailyGithub, with 9 commits."},
+		{"Streak waiting", ActivitySummary{streak: 4, reviews: 2},
+			"You haven't contributed yet today, but you're on a 4 day streak. This week you've made 0 commits, opened 0 pull requests and reviewed 2 pull requests."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.sum.describe(); got != tt.want {
+				t.Errorf("ActivitySummary.describe() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
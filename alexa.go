@@ -8,7 +8,6 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/http/httputil"
-	"strconv"
 	"strings"
 	"time"
 
@@ -33,17 +32,35 @@ const (
 	// Force a link account card to appear in the Alexa app
 	AlexaCardTypeLink = "LinkAccount"
 
+	// Dialog states, and the directive that lets Alexa collect missing slots itself
+	AlexaDialogCompleted   = "COMPLETED"
+	AlexaDirectiveDelegate = "Dialog.Delegate"
+
 	// Alexa built-in intents we must handle
 	AlexaHelpIntent   = "AMAZON.HelpIntent"
 	AlexaCancelIntent = "AMAZON.CancelIntent"
 	AlexaStopIntent   = "AMAZON.StopIntent"
+	AlexaYesIntent    = "AMAZON.YesIntent"
+	AlexaNoIntent     = "AMAZON.NoIntent"
+	AlexaNextIntent   = "AMAZON.NextIntent"
+	AlexaPrevIntent   = "AMAZON.PreviousIntent"
+	AlexaMoreIntent   = "AMAZON.MoreIntent"
 
 	// SSML speech constants
-	HelpText         = "<speak>You can ask for a summary of your Github profile, a list of trending repos, a list of your notifications, or a list of issues assigned to you.</speak>"
+	HelpText         = "<speak>You can ask for your daily briefing, a summary of your Github profile, your contribution streak, a digest of your org, a list of trending repos, a search for repos about a topic, anyone's public profile or repo, a list of your notifications, pull requests waiting on your review, the status of your own pull requests, how your repos are doing, new releases in repos you star or watch, what you starred lately, your latest gists, open security alerts, or a list of issues assigned to you. Say next or previous to page through a list, or tell me more about number 2 to hear the details of an item. After hearing your issues, you can comment on, close or reopen them by number. Ask whether a branch passed its builds, then say re-run the failed jobs if it did not. After hearing trending repos, say star number 2 or watch number 1. You can create an issue by saying create an issue in a repo titled something. You can also set your default language, list length, verbosity and timezone. After hearing your notifications, you can mark them as read. You can also ask for just your mentions or review requests, or the notifications in one repo.</speak>"
 	WelcomeText      = "<speak>Welcome to DailyGithub! Let's get started. Ask for a summary of your Github profile, a list of trending repos, a list of your notifications, or a list of issues assigned to you.</speak>"
 	AuthRequiredText = "<speak>This task requires linking your Github account to this skill.</speak>"
 )
 
+// Alexa built-in intents that map onto our own platform independent intents
+var alexaIntentNames = map[string]string{
+	AlexaYesIntent:  YesIntent,
+	AlexaNoIntent:   NoIntent,
+	AlexaNextIntent: NextIntent,
+	AlexaPrevIntent: PreviousIntent,
+	AlexaMoreIntent: NextIntent,
+}
+
 // Make a string have the buildFulfillment method
 type AlexaStringResponse string
 
@@ -59,8 +76,9 @@ type AlexaRequest struct {
 }
 
 type AlexaRequestDetails struct {
-	Type   string      `json:"type,omitempty"`
-	Intent AlexaIntent `json:"intent,omitempty"`
+	Type        string      `json:"type,omitempty"`
+	DialogState string      `json:"dialogState,omitempty"`
+	Intent      AlexaIntent `json:"intent,omitempty"`
 }
 
 type AlexaIntent struct {
@@ -69,8 +87,23 @@ type AlexaIntent struct {
 }
 
 type AlexaSlots struct {
-	Number AlexaSlot `json:"number,omitempty"`
-	Lang   AlexaSlot `json:"lang,omitempty"`
+	Number    AlexaSlot `json:"number,omitempty"`
+	Lang      AlexaSlot `json:"lang,omitempty"`
+	Verbosity AlexaSlot `json:"verbosity,omitempty"`
+	Timezone  AlexaSlot `json:"timezone,omitempty"`
+	Repo      AlexaSlot `json:"repo,omitempty"`
+	Reason    AlexaSlot `json:"reason,omitempty"`
+	Type      AlexaSlot `json:"type,omitempty"`
+	Label     AlexaSlot `json:"label,omitempty"`
+	Milestone AlexaSlot `json:"milestone,omitempty"`
+	Sort      AlexaSlot `json:"sort,omitempty"`
+	Title     AlexaSlot `json:"title,omitempty"`
+	Body      AlexaSlot `json:"body,omitempty"`
+	Branch    AlexaSlot `json:"branch,omitempty"`
+	Org       AlexaSlot `json:"org,omitempty"`
+	Topic     AlexaSlot `json:"topic,omitempty"`
+	Stars     AlexaSlot `json:"stars,omitempty"`
+	Login     AlexaSlot `json:"login,omitempty"`
 }
 
 type AlexaSlot struct {
@@ -79,22 +112,30 @@ type AlexaSlot struct {
 }
 
 type AlexaSession struct {
-	User AlexaUser `json:"user,omitempty"`
+	User       AlexaUser     `json:"user,omitempty"`
+	Attributes *SessionState `json:"attributes,omitempty"`
 }
 
 type AlexaUser struct {
+	UserId      string `json:"userId,omitempty"`
 	AccessToken string `json:"accessToken,omitempty"`
 }
 
 type AlexaResponse struct {
-	Version  string               `json:"version,omitempty"`
-	Response AlexaResponseDetails `json:"response,omitempty"`
+	Version           string               `json:"version,omitempty"`
+	SessionAttributes *SessionState        `json:"sessionAttributes,omitempty"`
+	Response          AlexaResponseDetails `json:"response,omitempty"`
 }
 
 type AlexaResponseDetails struct {
-	OutputSpeech     AlexaOutputSpeech `json:"outputSpeech,omitempty"`
-	Card             *AlexaCard        `json:"card,omitempty"` // Pointer here to omit "card:{}" when empty struct
-	ShouldEndSession bool              `json:"shouldEndSession"`
+	OutputSpeech     *AlexaOutputSpeech `json:"outputSpeech,omitempty"` // Pointer since dialog directives can't have speech
+	Card             *AlexaCard         `json:"card,omitempty"`         // Pointer here to omit "card:{}" when empty struct
+	Directives       []AlexaDirective   `json:"directives,omitempty"`
+	ShouldEndSession bool               `json:"shouldEndSession"`
+}
+
+type AlexaDirective struct {
+	Type string `json:"type"`
 }
 
 type AlexaCard struct {
@@ -109,16 +150,98 @@ type AlexaOutputSpeech struct {
 func NewAlexaResponse(ssml string) AlexaResponse {
 	ar := AlexaResponse{}
 	ar.Version = AlexaVersion
-	ar.Response.OutputSpeech.Type = "SSML"
-	ar.Response.OutputSpeech.SSML = ssml
+	ar.Response.OutputSpeech = &AlexaOutputSpeech{Type: "SSML", SSML: ssml}
 	ar.Response.ShouldEndSession = true
 	return ar
 }
 
+// Hand the turn back to Alexa so it can prompt for the slots the dialog model still needs.
+func NewAlexaDelegateResponse(session *SessionState) AlexaResponse {
+	ar := AlexaResponse{Version: AlexaVersion, SessionAttributes: session}
+	ar.Response.Directives = []AlexaDirective{{AlexaDirectiveDelegate}}
+	return ar
+}
+
+// Intents whose slots are collected through Alexa's dialog model before we see them
+func requiresDialog(name string) bool {
+	return name == CreateIssueIntent
+}
+
+func buildAlexaResponse(ctx context.Context, builder FulfillmentBuilder) AlexaResponse {
+	str := builder.buildFulfillment(ctx).Speech
+	str = strings.Replace(str, "&", "and", -1) // Alexa won't read ssml with '&' in it
+	resp := NewAlexaResponse(str)
+
+	// Keep the session open while there's state for the next turn
+	if state := nextSessionState(ctx, builder); state != nil {
+		resp.SessionAttributes = state
+		resp.Response.ShouldEndSession = false
+	}
+	return resp
+}
+
 func requiresAccessToken(name string) bool {
 	return name == SummaryIntent ||
 		name == NotificationsIntent ||
-		name == AssignedIssuesIntent
+		name == AssignedIssuesIntent ||
+		name == BriefingIntent ||
+		name == ReviewRequestsIntent ||
+		name == MyPullRequestsIntent ||
+		name == RepoReportIntent ||
+		name == CreateIssueIntent ||
+		name == CommentIntent ||
+		name == CloseIssueIntent ||
+		name == ReopenIssueIntent ||
+		name == StarIntent ||
+		name == UnstarIntent ||
+		name == WatchIntent ||
+		name == WorkflowRunsIntent ||
+		name == ReleasesIntent ||
+		name == SecurityAlertsIntent ||
+		name == ActivityIntent ||
+		name == OrgDigestIntent ||
+		name == StarredReposIntent ||
+		name == GistsIntent ||
+		name == RerunIntent ||
+		name == MarkAllReadIntent ||
+		name == MarkReadIntent
+}
+
+func alexaIntentRequest(alexaReq *AlexaRequest) *IntentRequest {
+	name := alexaReq.Request.Intent.Name
+	if mapped, ok := alexaIntentNames[name]; ok {
+		name = mapped
+	}
+
+	session := alexaReq.Session.Attributes
+	if session == nil {
+		session = &SessionState{}
+	}
+
+	return &IntentRequest{
+		Name:        name,
+		AccessToken: alexaReq.Session.User.AccessToken,
+		Number:      alexaReq.Request.Intent.Slots.Number.Value,
+		Lang:        alexaReq.Request.Intent.Slots.Lang.Value,
+		Verbosity:   alexaReq.Request.Intent.Slots.Verbosity.Value,
+		Timezone:    alexaReq.Request.Intent.Slots.Timezone.Value,
+		Repo:        alexaReq.Request.Intent.Slots.Repo.Value,
+		Reason:      alexaReq.Request.Intent.Slots.Reason.Value,
+		Type:        alexaReq.Request.Intent.Slots.Type.Value,
+		Label:       alexaReq.Request.Intent.Slots.Label.Value,
+		Milestone:   alexaReq.Request.Intent.Slots.Milestone.Value,
+		Sort:        alexaReq.Request.Intent.Slots.Sort.Value,
+		Title:       alexaReq.Request.Intent.Slots.Title.Value,
+		Body:        alexaReq.Request.Intent.Slots.Body.Value,
+		Branch:      alexaReq.Request.Intent.Slots.Branch.Value,
+		Org:         alexaReq.Request.Intent.Slots.Org.Value,
+		Topic:       alexaReq.Request.Intent.Slots.Topic.Value,
+		Stars:       alexaReq.Request.Intent.Slots.Stars.Value,
+		Login:       alexaReq.Request.Intent.Slots.Login.Value,
+		UserID:      platformUserID("alexa", alexaReq.Session.User.UserId),
+		Timeout:     10 * time.Second,
+		Session:     session,
+	}
 }
 
 func alexaHandler(w http.ResponseWriter, r *http.Request) {
@@ -155,8 +278,11 @@ func alexaHandler(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
+		intentReq := alexaIntentRequest(alexaReq)
+		ctx = userContext(ctx, intentReq)
+
 		// Make sure that access_token is valid if invoking an intent requiring an access token
-		if alexaReq.Session.User.AccessToken == "" && requiresAccessToken(alexaReq.Request.Intent.Name) {
+		if intentReq.AccessToken == "" && requiresAccessToken(intentReq.Name) {
 			resp := NewAlexaResponse(AuthRequiredText)
 			card := AlexaCard{AlexaCardTypeLink}
 			resp.Response.Card = &card
@@ -169,21 +295,17 @@ func alexaHandler(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
-		switch alexaReq.Request.Intent.Name {
-		case SummaryIntent:
-			builder, err = getProfileSummary(ctx, alexaReq.Session.User.AccessToken)
-		case TrendingReposIntent:
-			ctxWithDeadline, _ := context.WithTimeout(ctx, 10*time.Second) // This call sometimes takes a while
-			client := urlfetch.Client(ctxWithDeadline)
-			if i, err := strconv.Atoi(alexaReq.Request.Intent.Slots.Number.Value); err == nil && i != 0 {
-				builder, err = getTrending(ctx, client, &i, extractLang(client, alexaReq.Request.Intent.Slots.Lang.Value))
-			} else {
-				builder, err = getTrending(ctx, client, nil, extractLang(client, alexaReq.Request.Intent.Slots.Lang.Value))
+		if requiresDialog(intentReq.Name) && alexaReq.Request.DialogState != AlexaDialogCompleted {
+			jsonResp, err := json.Marshal(NewAlexaDelegateResponse(intentReq.Session))
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
 			}
-		case NotificationsIntent:
-			builder, err = getNotifications(ctx, alexaReq.Session.User.AccessToken)
-		case AssignedIssuesIntent:
-			builder, err = getAssignedIssues(ctx, alexaReq.Session.User.AccessToken)
+			w.Write(jsonResp)
+			return
+		}
+
+		switch alexaReq.Request.Intent.Name {
 		case AlexaHelpIntent:
 			resp := AlexaStringResponse(HelpText)
 			builder, err = &resp, nil
@@ -191,8 +313,7 @@ func alexaHandler(w http.ResponseWriter, r *http.Request) {
 			resp := AlexaStringResponse("") // Just stop whatever is going on
 			builder, err = &resp, nil
 		default:
-			http.Error(w, "Incorrect fullfillment action", http.StatusInternalServerError)
-			return
+			builder, err = fulfillIntent(ctx, intentReq)
 		}
 
 		if err != nil {
@@ -200,10 +321,7 @@ func alexaHandler(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
-		str := builder.buildFulfillment(ctx).Speech
-		str = strings.Replace(str, "&", "and", -1) // Alexa won't read ssml with '&' in it
-
-		alexaResp := NewAlexaResponse(str)
+		alexaResp := buildAlexaResponse(ctx, builder)
 		if alexaReq.Request.Intent.Name == AlexaHelpIntent {
 			alexaResp.Response.ShouldEndSession = false // Session does not end on Launch intent or Help intent
 		}
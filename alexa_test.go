@@ -1,6 +1,9 @@
 package main
 
-import "testing"
+import (
+	"encoding/json"
+	"testing"
+)
 
 func Test_requiresAccessToken(t *testing.T) {
 	type args struct {
@@ -14,9 +17,33 @@ func Test_requiresAccessToken(t *testing.T) {
 		{"Require", args{"summary_intent"}, true},
 		{"Require2", args{"notifications_intent"}, true},
 		{"Require3", args{"assigned_issues_intent"}, true},
+		{"Require4", args{"briefing_intent"}, true},
+		{"Require5", args{"review_requests_intent"}, true},
+		{"Require6", args{"my_pull_requests_intent"}, true},
+		{"Require7", args{"mark_all_read_intent"}, true},
+		{"Require8", args{"mark_read_intent"}, true},
+		{"Require9", args{"repo_report_intent"}, true},
+		{"Require10", args{"create_issue_intent"}, true},
+		{"Require11", args{"comment_intent"}, true},
+		{"Require12", args{"close_issue_intent"}, true},
+		{"Require13", args{"reopen_issue_intent"}, true},
+		{"Require14", args{"star_intent"}, true},
+		{"Require15", args{"unstar_intent"}, true},
+		{"Require16", args{"watch_intent"}, true},
+		{"Require17", args{"workflow_runs_intent"}, true},
+		{"Require18", args{"rerun_failed_jobs_intent"}, true},
+		{"Require19", args{"releases_intent"}, true},
+		{"Require20", args{"security_alerts_intent"}, true},
+		{"Require21", args{"activity_intent"}, true},
+		{"Require22", args{"org_digest_intent"}, true},
+		{"Require23", args{"starred_repos_intent"}, true},
+		{"Require24", args{"gists_intent"}, true},
 		{"NotRequired", args{"trending_repos_intent"}, false},
 		{"NotRequired2", args{"fake_intent"}, false},
 		{"NotRequired3", args{""}, false},
+		{"NotRequired4", args{"search_repos_intent"}, false},
+		{"NotRequired5", args{"lookup_user_intent"}, false},
+		{"NotRequired6", args{"lookup_repo_intent"}, false},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -26,3 +53,15 @@ func Test_requiresAccessToken(t *testing.T) {
 		})
 	}
 }
+
+func Test_NewAlexaDelegateResponse(t *testing.T) {
+	b, err := json.Marshal(NewAlexaDelegateResponse(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `{"version":"1.0","response":{"directives":[{"type":"Dialog.Delegate"}],"shouldEndSession":false}}`
+	if string(b) != want {
+		t.Errorf("NewAlexaDelegateResponse() = %s, want %s", b, want)
+	}
+}
@@ -6,19 +6,68 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httputil"
-	"strconv"
 	"time"
 
 	"google.golang.org/appengine"
 	"google.golang.org/appengine/log"
-	"google.golang.org/appengine/urlfetch"
 )
 
+const AssistantAuthRequiredText = "This task requires linking your Github account. You can link it in the Google Home app."
+
 func init() {
 	http.HandleFunc("/", assistantHandler)
 	http.HandleFunc("/authorize", assistantAuth)
 }
 
+func assistantIntentRequest(fulfillmentReq *FulfillmentReq) *IntentRequest {
+	return &IntentRequest{
+		Name:        fulfillmentReq.Result.Action,
+		AccessToken: fulfillmentReq.OriginalRequest.Data.User.AccessToken,
+		Number:      fulfillmentReq.Result.Parameters.Number,
+		Lang:        fulfillmentReq.Result.Parameters.Lang,
+		Verbosity:   fulfillmentReq.Result.Parameters.Verbosity,
+		Timezone:    fulfillmentReq.Result.Parameters.Timezone,
+		Repo:        fulfillmentReq.Result.Parameters.Repo,
+		Reason:      fulfillmentReq.Result.Parameters.Reason,
+		Type:        fulfillmentReq.Result.Parameters.Type,
+		Label:       fulfillmentReq.Result.Parameters.Label,
+		Milestone:   fulfillmentReq.Result.Parameters.Milestone,
+		Sort:        fulfillmentReq.Result.Parameters.Sort,
+		Title:       fulfillmentReq.Result.Parameters.Title,
+		Body:        fulfillmentReq.Result.Parameters.Body,
+		Branch:      fulfillmentReq.Result.Parameters.Branch,
+		Org:         fulfillmentReq.Result.Parameters.Org,
+		Topic:       fulfillmentReq.Result.Parameters.Topic,
+		Stars:       fulfillmentReq.Result.Parameters.Stars,
+		Login:       fulfillmentReq.Result.Parameters.Login,
+		UserID:      platformUserID("assistant", fulfillmentReq.OriginalRequest.Data.User.UserId),
+		Timeout:     20 * time.Second,
+		Session:     sessionFromContexts(fulfillmentReq.Result.Contexts),
+	}
+}
+
+// Like the Alexa skill, ask the user to link their account before any intent that needs it.
+func assistantFulfill(ctx context.Context, intentReq *IntentRequest) (FulfillmentBuilder, error) {
+	if intentReq.AccessToken == "" && requiresAccessToken(intentReq.Name) {
+		return &SpokenResponse{AssistantAuthRequiredText, AssistantAuthRequiredText, nil}, nil
+	}
+	return fulfillIntent(ctx, intentReq)
+}
+
+func buildAssistantResponse(ctx context.Context, builder FulfillmentBuilder) *AssistantResp {
+	state := nextSessionState(ctx, builder)
+	resp := &AssistantResp{
+		FulfillmentResp: builder.buildFulfillment(ctx),
+		ContextOut:      []FulfillmentContext{sessionToContext(state)},
+	}
+
+	// Only override Dialogflow's end of conversation setting when we're waiting on a reply
+	if state != nil {
+		resp.Data = &AssistantData{AssistantGoogleData{ExpectUserResponse: true}}
+	}
+	return resp
+}
+
 func assistantHandler(w http.ResponseWriter, r *http.Request) {
 	ctx := appengine.NewContext(r)
 
@@ -35,35 +84,15 @@ func assistantHandler(w http.ResponseWriter, r *http.Request) {
 
 		w.Header().Set("Content-Type", "application/json")
 
-		var builder FulfillmentBuilder
-		switch fulfillmentReq.Result.Action {
-		case SummaryIntent:
-			builder, err = getProfileSummary(ctx, fulfillmentReq.OriginalRequest.Data.User.AccessToken)
-		case TrendingReposIntent:
-			ctxWithDeadline, _ := context.WithTimeout(ctx, 20*time.Second) // This call sometimes takes a while
-			client := urlfetch.Client(ctxWithDeadline)
-			if i, err := strconv.Atoi(fulfillmentReq.Result.Parameters.Number); err == nil && i != 0 {
-				builder, err = getTrending(ctx, client, &i, extractLang(client, fulfillmentReq.Result.Parameters.Lang))
-			} else {
-				builder, err = getTrending(ctx, client, nil, extractLang(client, fulfillmentReq.Result.Parameters.Lang))
-			}
-		case NotificationsIntent:
-			builder, err = getNotifications(ctx, fulfillmentReq.OriginalRequest.Data.User.AccessToken)
-		case AssignedIssuesIntent:
-			builder, err = getAssignedIssues(ctx, fulfillmentReq.OriginalRequest.Data.User.AccessToken)
-		default:
-			http.Error(w, "Incorrect fullfillment action", http.StatusInternalServerError)
-			return
-		}
-
+		intentReq := assistantIntentRequest(fulfillmentReq)
+		ctx = userContext(ctx, intentReq)
+		builder, err := assistantFulfill(ctx, intentReq)
 		if err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
 
-		fulfillmentResp := builder.buildFulfillment(ctx)
-
-		resp, err := json.Marshal(fulfillmentResp)
+		resp, err := json.Marshal(buildAssistantResponse(ctx, builder))
 		if err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
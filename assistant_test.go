@@ -0,0 +1,32 @@
+package main
+
+import (
+	"context"
+	"io/ioutil"
+	"testing"
+)
+
+func Test_assistantFulfillWithoutToken(t *testing.T) {
+	ctx := withCLI(context.Background(), &cliOptions{ioutil.Discard, nil})
+	tests := []struct {
+		name   string
+		intent string
+	}{
+		{"Activity", ActivityIntent},
+		{"Notifications", NotificationsIntent},
+		{"MarkAllRead", MarkAllReadIntent},
+		{"Star", StarIntent},
+		{"Watch", WatchIntent},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			builder, err := assistantFulfill(ctx, &IntentRequest{Name: tt.intent})
+			if err != nil {
+				t.Fatalf("assistantFulfill() error = %v", err)
+			}
+			if got := builder.(*SpokenResponse).text; got != AssistantAuthRequiredText {
+				t.Errorf("assistantFulfill() = %v, want %v", got, AssistantAuthRequiredText)
+			}
+		})
+	}
+}
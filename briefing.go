@@ -0,0 +1,306 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/github"
+	"google.golang.org/appengine/datastore"
+)
+
+const (
+	briefingAssignedIssues = "Newly assigned issues" // Section title
+	briefingTrendingRepos  = 3
+	briefingIssues         = 3
+	briefingNotifications  = 50 // We only count these, so one page is plenty
+
+	assignedSnapshotKind = "AssignedSnapshot" // Use to store in Cloud Datastore
+	maxAssignedPages     = 3                  // Open assigned issues to compare, 100 per page
+)
+
+// The open issues assigned to the user at their last briefing, so the next one can say which are new
+type AssignedSnapshot struct {
+	Issues  []string // Refs like "golang/go#123"
+	TakenAt time.Time
+}
+
+// A single section of the daily briefing, like "Notifications" or "Trending"
+type briefingSection struct {
+	title, text, speech string
+}
+
+type Briefing struct {
+	sections []*briefingSection
+	skipped  []string // Titles of sections that failed or didn't finish in time
+}
+
+type briefingFetcher struct {
+	title string
+	fetch func(ctx context.Context) (*briefingSection, error)
+}
+
+// Fetch every section of the briefing in parallel. Anything that hasn't come back by the
+// time ctx expires is dropped rather than failing the whole briefing.
+func getBriefing(ctx context.Context, accessToken, userID string, client *http.Client, lang string) (FulfillmentBuilder, error) {
+	ghClient := createGithubClient(ctx, accessToken)
+	fetchers := []briefingFetcher{
+		{"Profile", func(ctx context.Context) (*briefingSection, error) { return briefProfile(ctx, ghClient) }},
+		{"Notifications", func(ctx context.Context) (*briefingSection, error) { return briefNotifications(ctx, ghClient) }},
+		{briefingAssignedIssues, func(ctx context.Context) (*briefingSection, error) {
+			return briefAssignedIssues(ctx, ghClient, userID)
+		}},
+		{"Trending", func(ctx context.Context) (*briefingSection, error) {
+			return briefTrending(ctx, client, extractLang(client, lang))
+		}},
+	}
+
+	briefing, err := runBriefing(ctx, fetchers)
+	if err != nil {
+		return nil, err
+	}
+	return briefing, nil
+}
+
+// Run the fetchers and put their sections together in order, leaving out any that fail or
+// are still running when ctx expires.
+func runBriefing(ctx context.Context, fetchers []briefingFetcher) (*Briefing, error) {
+	type result struct {
+		index   int
+		section *briefingSection
+		err     error
+	}
+
+	results := make(chan result, len(fetchers)) // Buffered so stragglers never block after we stop listening
+	for i, fetcher := range fetchers {
+		go func(i int, fetcher briefingFetcher) {
+			section, err := fetcher.fetch(ctx)
+			results <- result{i, section, err}
+		}(i, fetcher)
+	}
+
+	sections := make([]*briefingSection, len(fetchers))
+	for received := 0; received < len(fetchers); received++ {
+		select {
+		case r := <-results:
+			if r.err != nil {
+				debugf(ctx, "Briefing section %s failed: %v", fetchers[r.index].title, r.err)
+				continue
+			}
+			sections[r.index] = r.section
+		case <-ctx.Done():
+			debugf(ctx, "Briefing deadline hit after %d of %d sections", received, len(fetchers))
+			received = len(fetchers)
+		}
+	}
+
+	briefing := &Briefing{}
+	for i, section := range sections {
+		if section == nil {
+			briefing.skipped = append(briefing.skipped, fetchers[i].title)
+			continue
+		}
+		briefing.sections = append(briefing.sections, section)
+	}
+
+	if len(briefing.sections) == 0 {
+		return nil, errors.New("no briefing sections could be fetched")
+	}
+	return briefing, nil
+}
+
+func briefProfile(ctx context.Context, client *github.Client) (*briefingSection, error) {
+	profile, err := fetchProfileSummary(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := fmt.Sprintf("Good morning %s. ", profile.user.GetName())
+	if changes := profile.changes(ctx); changes != "" {
+		summary += changes
+	} else {
+		summary += fmt.Sprintf("You have %d followers and %d public repos.", profile.user.GetFollowers(), profile.user.GetPublicRepos())
+	}
+	return &briefingSection{"Profile", summary, summary}, nil
+}
+
+func briefNotifications(ctx context.Context, client *github.Client) (*briefingSection, error) {
+	opt := &github.NotificationListOptions{ListOptions: github.ListOptions{PerPage: briefingNotifications}}
+	notifications, resp, err := client.Activity.ListNotifications(ctx, opt)
+	if err != nil {
+		return nil, err
+	}
+
+	var summary string
+	switch {
+	case resp.NextPage != 0:
+		summary = fmt.Sprintf("You have more than %d unread notifications.", briefingNotifications)
+	case len(notifications) == 0:
+		summary = "You have no unread notifications."
+	case len(notifications) == 1:
+		summary = "You have 1 unread notification."
+	default:
+		summary = fmt.Sprintf("You have %d unread notifications.", len(notifications))
+	}
+	return &briefingSection{"Notifications", summary, summary}, nil
+}
+
+func assignedSnapshotKey(ctx context.Context, userID string) *datastore.Key {
+	return datastore.NewKey(ctx, assignedSnapshotKind, userID, 0, nil)
+}
+
+func loadAssignedSnapshot(ctx context.Context, userID string) *AssignedSnapshot {
+	if cliFromContext(ctx) != nil || userID == "" {
+		return nil
+	}
+
+	snapshot := &AssignedSnapshot{}
+	if err := datastore.Get(ctx, assignedSnapshotKey(ctx, userID), snapshot); err != nil {
+		if err != datastore.ErrNoSuchEntity {
+			debugf(ctx, "Failed to load assigned issues snapshot: %v", err)
+		}
+		return nil
+	}
+	return snapshot
+}
+
+func saveAssignedSnapshot(ctx context.Context, userID string, snapshot *AssignedSnapshot) {
+	if cliFromContext(ctx) != nil || userID == "" {
+		return
+	}
+
+	if _, err := datastore.Put(ctx, assignedSnapshotKey(ctx, userID), snapshot); err != nil {
+		debugf(ctx, "Failed to save assigned issues snapshot: %v", err)
+	}
+}
+
+func listAssignedIssues(ctx context.Context, client *github.Client) ([]*github.Issue, error) {
+	opt := &github.IssueListOptions{Filter: "assigned", State: "open", ListOptions: github.ListOptions{PerPage: 100}}
+	var assigned []*github.Issue
+	for page := 0; page < maxAssignedPages; page++ {
+		issues, resp, err := client.Issues.List(ctx, true, opt)
+		if err != nil {
+			return nil, err
+		}
+
+		assigned = append(assigned, issues...)
+		if resp.NextPage == 0 {
+			break
+		}
+		opt.Page = resp.NextPage
+	}
+	return assigned, nil
+}
+
+func assignedIssueRef(issue *github.Issue) string {
+	return issueRef(issue.Repository.GetFullName(), issue.GetNumber())
+}
+
+// Issues in current that weren't assigned at the last briefing.
+func newAssignedIssues(previous []string, current []*github.Issue) []*github.Issue {
+	seen := map[string]bool{}
+	for _, ref := range previous {
+		seen[ref] = true
+	}
+
+	var added []*github.Issue
+	for _, issue := range current {
+		if !seen[assignedIssueRef(issue)] {
+			added = append(added, issue)
+		}
+	}
+	return added
+}
+
+// Issues assigned to the user since their last briefing. The API can't say when an issue was
+// assigned, so the open assigned issues are compared against the ones seen last time.
+func briefAssignedIssues(ctx context.Context, client *github.Client, userID string) (*briefingSection, error) {
+	issues, err := listAssignedIssues(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+
+	var refs []string
+	for _, issue := range issues {
+		refs = append(refs, assignedIssueRef(issue))
+	}
+	previous := loadAssignedSnapshot(ctx, userID)
+	saveAssignedSnapshot(ctx, userID, &AssignedSnapshot{refs, time.Now()})
+
+	if previous == nil {
+		return describeAssignedIssues(nil, len(issues), true), nil
+	}
+	return describeAssignedIssues(newAssignedIssues(previous.Issues, issues), len(issues), false), nil
+}
+
+// Read out the newly assigned issues. With nothing to compare against the first time, just
+// count the open ones.
+func describeAssignedIssues(added []*github.Issue, open int, firstTime bool) *briefingSection {
+	if firstTime {
+		summary := fmt.Sprintf("You have %s assigned to you. From your next briefing, I'll tell you which ones are new.",
+			pluralize(open, "open issue", "open issues"))
+		return &briefingSection{briefingAssignedIssues, summary, summary}
+	}
+	if len(added) == 0 {
+		summary := "No new issues have been assigned to you since your last briefing."
+		return &briefingSection{briefingAssignedIssues, summary, summary}
+	}
+
+	header := fmt.Sprintf("%s assigned to you since your last briefing:", pluralize(len(added), "issue was", "issues were"))
+	section := &briefingSection{briefingAssignedIssues, header, "<p>" + header + "</p>"}
+	for i, issue := range added {
+		if i >= briefingIssues {
+			break
+		}
+		section.speech += fmt.Sprintf("<p>%s in %s.</p>", issue.GetTitle(), issue.Repository.GetName())
+		section.text += fmt.Sprintf("\n- %s in %s", issue.GetTitle(), issue.Repository.GetName())
+	}
+	return section
+}
+
+func briefTrending(ctx context.Context, client *http.Client, lang string) (*briefingSection, error) {
+	count := briefingTrendingRepos
+	builder, err := getTrending(ctx, client, &count, lang)
+	if err != nil {
+		return nil, err
+	}
+
+	list := builder.(*PagedList)
+	text, speech := list.render(0, len(list.Items))
+	return &briefingSection{"Trending", text, list.SpeechHeader + speech}, nil
+}
+
+func (briefing *Briefing) buildFulfillment(ctx context.Context) *FulfillmentResp {
+	var text, speech string
+	for _, section := range briefing.sections {
+		text += fmt.Sprintf("%s\n%s\n\n", section.title, section.text)
+		speech += fmt.Sprintf(`<p>%s</p>%s<break time="500ms"/>`, section.title, section.speech)
+	}
+
+	if len(briefing.skipped) > 0 {
+		text += fmt.Sprintf("Couldn't load: %s", joinWords(briefing.skipped))
+		speech += fmt.Sprintf("<p>I couldn't load your %s.</p>", strings.ToLower(joinWords(briefing.skipped)))
+	}
+
+	debugf(ctx, "Built fulfillment with string: %s", speech)
+	return &FulfillmentResp{"<speak>" + speech + "</speak>", text}
+}
+
+// Join words into a spoken list: "a", "a and b", "a, b and c"
+func joinWords(words []string) string {
+	switch len(words) {
+	case 0:
+		return ""
+	case 1:
+		return words[0]
+	}
+
+	result := words[0]
+	for _, word := range words[1 : len(words)-1] {
+		result += ", " + word
+	}
+	return result + " and " + words[len(words)-1]
+}
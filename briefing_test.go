@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/github"
+)
+
+func Test_joinWords(t *testing.T) {
+	tests := []struct {
+		name  string
+		words []string
+		want  string
+	}{
+		{"Empty", nil, ""},
+		{"One word", []string{"profile"}, "profile"},
+		{"Two words", []string{"profile", "trending"}, "profile and trending"},
+		{"Three words", []string{"profile", "notifications", "trending"}, "profile, notifications and trending"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := joinWords(tt.words); got != tt.want {
+				t.Errorf("joinWords() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_runBriefing(t *testing.T) {
+	section := func(title string) briefingFetcher {
+		return briefingFetcher{title, func(ctx context.Context) (*briefingSection, error) {
+			return &briefingSection{title, title + " text", title + " speech"}, nil
+		}}
+	}
+	failing := func(title string) briefingFetcher {
+		return briefingFetcher{title, func(ctx context.Context) (*briefingSection, error) {
+			return nil, errors.New("server error")
+		}}
+	}
+	hanging := func(title string) briefingFetcher {
+		return briefingFetcher{title, func(ctx context.Context) (*briefingSection, error) {
+			<-ctx.Done() // Only gives up when the briefing does
+			return nil, ctx.Err()
+		}}
+	}
+
+	tests := []struct {
+		name         string
+		fetchers     []briefingFetcher
+		wantSections []string
+		wantSkipped  []string
+		wantErr      bool
+	}{
+		{"All sections", []briefingFetcher{section("Profile"), section("Trending")}, []string{"Profile", "Trending"}, nil, false},
+		{"Failed section", []briefingFetcher{section("Profile"), failing("Notifications"), section("Trending")},
+			[]string{"Profile", "Trending"}, []string{"Notifications"}, false},
+		{"Deadline", []briefingFetcher{hanging("Profile"), section("Notifications"), hanging("Trending")},
+			[]string{"Notifications"}, []string{"Profile", "Trending"}, false},
+		{"Nothing came back", []briefingFetcher{failing("Profile"), hanging("Trending")}, nil, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(withCLI(context.Background(), &cliOptions{ioutil.Discard, nil}), 50*time.Millisecond)
+			defer cancel()
+
+			briefing, err := runBriefing(ctx, tt.fetchers)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("runBriefing() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			var titles []string
+			for _, section := range briefing.sections {
+				titles = append(titles, section.title)
+			}
+			if !reflect.DeepEqual(titles, tt.wantSections) || !reflect.DeepEqual(briefing.skipped, tt.wantSkipped) {
+				t.Errorf("runBriefing() sections = %v, skipped = %v, want %v, %v", titles, briefing.skipped, tt.wantSections, tt.wantSkipped)
+			}
+		})
+	}
+}
+
+func testAssignedIssue(repo string, number int, title string) *github.Issue {
+	return &github.Issue{
+		Number:     github.Int(number),
+		Title:      github.String(title),
+		Repository: &github.Repository{Name: github.String(repo), FullName: github.String("golang/" + repo)},
+	}
+}
+
+func Test_newAssignedIssues(t *testing.T) {
+	flaky := testAssignedIssue("go", 1, "Flaky test")
+	leak := testAssignedIssue("go", 2, "Memory leak")
+	tests := []struct {
+		name     string
+		previous []string
+		current  []*github.Issue
+		want     []*github.Issue
+	}{
+		{"Assigned", []string{"golang/go#1"}, []*github.Issue{flaky, leak}, []*github.Issue{leak}},
+		{"Closed", []string{"golang/go#1", "golang/go#2"}, []*github.Issue{leak}, nil},
+		{"Same", []string{"golang/go#1"}, []*github.Issue{flaky}, nil},
+		{"SameNumberOtherRepo", []string{"golang/tools#1"}, []*github.Issue{flaky}, []*github.Issue{flaky}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := newAssignedIssues(tt.previous, tt.current); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("newAssignedIssues() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_describeAssignedIssues(t *testing.T) {
+	flaky := testAssignedIssue("go", 1, "Flaky test")
+	leak := testAssignedIssue("tools", 2, "Memory leak")
+	tests := []struct {
+		name      string
+		added     []*github.Issue
+		open      int
+		firstTime bool
+		want      string
+	}{
+		{"FirstTime", nil, 4, true, "You have 4 open issues assigned to you. From your next briefing, I'll tell you which ones are new."},
+		{"NoneNew", nil, 4, false, "No new issues have been assigned to you since your last briefing."},
+		{"OneNew", []*github.Issue{flaky}, 4, false, "1 issue was assigned to you since your last briefing:\n- Flaky test in go"},
+		{"TwoNew", []*github.Issue{flaky, leak}, 4, false, "2 issues were assigned to you since your last briefing:\n- Flaky test in go\n- Memory leak in tools"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := describeAssignedIssues(tt.added, tt.open, tt.firstTime).text; got != tt.want {
+				t.Errorf("describeAssignedIssues() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
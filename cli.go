@@ -0,0 +1,262 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/*
+Command line client for running intents from a terminal, without handcrafting JSON for
+the Alexa or Assistant endpoints:
+
+	dailygithub trending --lang go --n 10
+	dailygithub notifications --format alexa
+	dailygithub issues
+	dailygithub briefing --lang rust
+
+The Github token comes from --token, then $DAILYGITHUB_TOKEN, then the token line of the
+config file (~/.dailygithub by default). The config file can also hold the same preferences
+Alexa and Assistant users set by voice:
+
+	token = <personal access token>
+	lang = rust
+	count = 3
+	verbosity = brief
+	timezone = America/Denver
+*/
+
+const (
+	cliTokenEnv        = "DAILYGITHUB_TOKEN"
+	cliConfigFile      = ".dailygithub"
+	cliTimeout         = 30 * time.Second
+	cliFormatText      = "text"
+	cliFormatSSML      = "ssml"
+	cliFormatAlexa     = "alexa"
+	cliFormatAssistant = "dialogflow"
+)
+
+// Map subcommands to the intents they run
+var cliCommands = map[string]string{
+	"summary":       SummaryIntent,
+	"trending":      TrendingReposIntent,
+	"search":        SearchReposIntent,
+	"user":          LookupUserIntent,
+	"repo":          LookupRepoIntent,
+	"notifications": NotificationsIntent,
+	"issues":        AssignedIssuesIntent,
+	"briefing":      BriefingIntent,
+	"reviews":       ReviewRequestsIntent,
+	"prs":           MyPullRequestsIntent,
+	"repos":         RepoReportIntent,
+	"runs":          WorkflowRunsIntent,
+	"releases":      ReleasesIntent,
+	"security":      SecurityAlertsIntent,
+	"activity":      ActivityIntent,
+	"org":           OrgDigestIntent,
+	"starred":       StarredReposIntent,
+	"gists":         GistsIntent,
+}
+
+type cliCommand struct {
+	request IntentRequest
+	format  string
+	config  string
+	verbose bool
+	prefs   *Preferences
+}
+
+func cliUsage(w io.Writer) {
+	var names []string
+	for name := range cliCommands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	fmt.Fprintf(w, "usage: dailygithub <%s> [flags]\n", strings.Join(names, "|"))
+}
+
+func parseCLIArgs(args []string, output io.Writer) (*cliCommand, error) {
+	if len(args) == 0 {
+		return nil, errors.New("missing command")
+	}
+
+	intent, ok := cliCommands[args[0]]
+	if !ok {
+		return nil, fmt.Errorf("unknown command %q", args[0])
+	}
+
+	cmd := &cliCommand{request: IntentRequest{Name: intent, Timeout: cliTimeout, Session: &SessionState{}}}
+	flags := flag.NewFlagSet(args[0], flag.ContinueOnError)
+	flags.SetOutput(output)
+	flags.StringVar(&cmd.request.Lang, "lang", "", "language to show trending repos for")
+	n := flags.Int("n", 0, "number of trending repos to show")
+	flags.StringVar(&cmd.request.Repo, "repo", "", "repository to narrow repos, notifications or issues to")
+	flags.StringVar(&cmd.request.Reason, "reason", "", "notification reasons to show, like mention,review_requested")
+	flags.StringVar(&cmd.request.Type, "type", "", "notification subject types to show, like PullRequest,Issue")
+	flags.StringVar(&cmd.request.Label, "label", "", "label to narrow issues to")
+	flags.StringVar(&cmd.request.Milestone, "milestone", "", "milestone to narrow issues to")
+	flags.StringVar(&cmd.request.Login, "login", "", "Github user or org to look up")
+	flags.StringVar(&cmd.request.Topic, "topic", "", "what to search repos for")
+	flags.StringVar(&cmd.request.Stars, "stars", "", "minimum stars for searched repos")
+	flags.StringVar(&cmd.request.Org, "org", "", "organization for the org digest")
+	flags.StringVar(&cmd.request.Branch, "branch", "", "branch to show workflow runs for (default the repo's default branch)")
+	flags.StringVar(&cmd.request.Sort, "sort", "", "issue order: oldest, newest, updated or comments")
+	flags.StringVar(&cmd.request.AccessToken, "token", "", "Github access token (default $"+cliTokenEnv+")")
+	flags.StringVar(&cmd.format, "format", cliFormatText, "output format: text, ssml, alexa or dialogflow")
+	flags.StringVar(&cmd.config, "config", "", "config file to read the token and preferences from (default ~/"+cliConfigFile+")")
+	flags.BoolVar(&cmd.verbose, "v", false, "log debug output to stderr")
+	if err := flags.Parse(args[1:]); err != nil {
+		return nil, err
+	}
+
+	if *n != 0 {
+		cmd.request.Number = strconv.Itoa(*n)
+	}
+
+	switch cmd.format {
+	case cliFormatText, cliFormatSSML, cliFormatAlexa, cliFormatAssistant:
+	default:
+		return nil, fmt.Errorf("unknown format %q", cmd.format)
+	}
+
+	return cmd, nil
+}
+
+// Read a config file of key = value lines. Lines starting with # are ignored.
+func readCLIConfig(r io.Reader) map[string]string {
+	config := map[string]string{}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.SplitN(line, "=", 2)
+		if len(parts) == 2 {
+			config[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+		}
+	}
+	return config
+}
+
+func preferencesFromConfig(config map[string]string) *Preferences {
+	prefs := &Preferences{Lang: config["lang"]}
+	if n, ok := resolveListLength(config["count"]); ok {
+		prefs.ListLength = n
+	}
+	if verbosity, ok := resolveVerbosity(config["verbosity"]); ok {
+		prefs.Verbosity = verbosity
+	}
+	if tz, ok := resolveTimezone(config["timezone"]); ok {
+		prefs.Timezone = tz
+	}
+	if reasons, ok := resolveSpokenList(config["notification_reasons"], notificationReasons); ok {
+		prefs.NotificationReasons = reasons
+	}
+	if types, ok := resolveSpokenList(config["notification_types"], notificationTypes); ok {
+		prefs.NotificationTypes = types
+	}
+	prefs.NotificationRepo = config["notification_repo"]
+	prefs.Org = config["org"]
+	for _, repo := range strings.Split(config["priority_repos"], ",") {
+		if repo = strings.TrimSpace(repo); repo != "" && len(prefs.PriorityRepos) < maxPriorityRepos {
+			prefs.PriorityRepos = append(prefs.PriorityRepos, repo)
+		}
+	}
+	return prefs
+}
+
+// Fill in the token and preferences from the environment and config file.
+func (cmd *cliCommand) loadConfig() {
+	path := cmd.config
+	if path == "" {
+		path = filepath.Join(os.Getenv("HOME"), cliConfigFile)
+	}
+
+	config := map[string]string{}
+	if f, err := os.Open(path); err == nil {
+		config = readCLIConfig(f)
+		f.Close()
+	}
+	cmd.prefs = preferencesFromConfig(config)
+
+	if cmd.request.AccessToken == "" {
+		cmd.request.AccessToken = os.Getenv(cliTokenEnv)
+	}
+	if cmd.request.AccessToken == "" {
+		cmd.request.AccessToken = config["token"]
+	}
+}
+
+// Run a CLI command, returning the process exit code.
+func runCLI(args []string, stdout, stderr io.Writer) int {
+	cmd, err := parseCLIArgs(args, stderr)
+	if err == flag.ErrHelp {
+		return 0
+	} else if err != nil {
+		fmt.Fprintln(stderr, err)
+		cliUsage(stderr)
+		return 2
+	}
+
+	cmd.loadConfig()
+	if cmd.request.AccessToken == "" && requiresAccessToken(cmd.request.Name) {
+		fmt.Fprintf(stderr, "%s requires a Github token: pass --token, set $%s or add it to ~/%s\n", args[0], cliTokenEnv, cliConfigFile)
+		return 1
+	}
+
+	logOutput := ioutil.Discard
+	if cmd.verbose {
+		logOutput = stderr
+	}
+	ctx, cancel := context.WithTimeout(withCLI(context.Background(), &cliOptions{logOutput, cmd.prefs}), cliTimeout)
+	defer cancel()
+	ctx = userContext(ctx, &cmd.request)
+
+	builder, err := fulfillIntent(ctx, &cmd.request)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+
+	if err := writeCLIOutput(ctx, stdout, cmd.format, builder); err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	return 0
+}
+
+func writeCLIOutput(ctx context.Context, w io.Writer, format string, builder FulfillmentBuilder) error {
+	var out interface{}
+	switch format {
+	case cliFormatText:
+		_, err := fmt.Fprintln(w, strings.TrimSpace(builder.buildFulfillment(ctx).DisplayText))
+		return err
+	case cliFormatSSML:
+		_, err := fmt.Fprintln(w, builder.buildFulfillment(ctx).Speech)
+		return err
+	case cliFormatAlexa:
+		out = buildAlexaResponse(ctx, builder)
+	case cliFormatAssistant:
+		out = buildAssistantResponse(ctx, builder)
+	}
+
+	b, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w, string(b))
+	return err
+}
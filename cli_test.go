@@ -0,0 +1,86 @@
+package main
+
+import (
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func Test_parseCLIArgs(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		want    IntentRequest
+		format  string
+		wantErr bool
+	}{
+		{"Trending with flags", []string{"trending", "--lang", "go", "--n", "10"}, IntentRequest{Name: TrendingReposIntent, Lang: "go", Number: "10"}, cliFormatText, false},
+		{"Format flag", []string{"notifications", "-format", "alexa"}, IntentRequest{Name: NotificationsIntent}, cliFormatAlexa, false},
+		{"Token flag", []string{"issues", "--token", "abc"}, IntentRequest{Name: AssignedIssuesIntent, AccessToken: "abc"}, cliFormatText, false},
+		{"Unknown command", []string{"fake"}, IntentRequest{}, "", true},
+		{"Unknown format", []string{"summary", "--format", "xml"}, IntentRequest{}, "", true},
+		{"Missing command", []string{}, IntentRequest{}, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseCLIArgs(tt.args, ioutil.Discard)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseCLIArgs() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			got.request.Timeout = 0
+			got.request.Session = nil
+			if got.request != tt.want || got.format != tt.format {
+				t.Errorf("parseCLIArgs() = %+v %v, want %+v %v", got.request, got.format, tt.want, tt.format)
+			}
+		})
+	}
+}
+
+func Test_readCLIConfig(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		key    string
+		want   string
+	}{
+		{"Token line", "token = abc123\n", "token", "abc123"},
+		{"Skips comments", "# token = old\ntoken=new", "token", "new"},
+		{"Other keys", "lang = go\n", "lang", "go"},
+		{"Missing key", "lang = go\n", "token", ""},
+		{"Empty file", "", "token", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := readCLIConfig(strings.NewReader(tt.config))[tt.key]; got != tt.want {
+				t.Errorf("readCLIConfig()[%q] = %v, want %v", tt.key, got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_preferencesFromConfig(t *testing.T) {
+	tests := []struct {
+		name   string
+		config map[string]string
+		want   Preferences
+	}{
+		{"Empty", map[string]string{}, Preferences{}},
+		{"Everything", map[string]string{"lang": "rust", "count": "3", "verbosity": "short", "timezone": "eastern"}, Preferences{Lang: "rust", ListLength: 3, Verbosity: VerbosityBrief, Timezone: "America/New_York"}},
+		{"Bad values are ignored", map[string]string{"count": "50", "verbosity": "loud", "timezone": "Nowhere/Land", "notification_reasons": "spam"}, Preferences{}},
+		{"Notification filter", map[string]string{"notification_reasons": "mention,review_requested", "notification_types": "PullRequest", "notification_repo": "kubernetes"},
+			Preferences{NotificationReasons: []string{"mention", "review_requested"}, NotificationTypes: []string{"PullRequest"}, NotificationRepo: "kubernetes"}},
+		{"Priority repos", map[string]string{"priority_repos": "kubernetes, golang/go,"}, Preferences{PriorityRepos: []string{"kubernetes", "golang/go"}}},
+		{"Org", map[string]string{"org": "golang"}, Preferences{Org: "golang"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := preferencesFromConfig(tt.config); !reflect.DeepEqual(*got, tt.want) {
+				t.Errorf("preferencesFromConfig() = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
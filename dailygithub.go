@@ -2,9 +2,14 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	stdlog "log"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
 	_ "net/http/pprof"
 
@@ -20,15 +25,28 @@ const (
 	TrendingReposIntent  = "trending_repos_intent"
 	NotificationsIntent  = "notifications_intent"
 	AssignedIssuesIntent = "assigned_issues_intent"
+	BriefingIntent       = "briefing_intent"
+	ReviewRequestsIntent = "review_requests_intent"
+	MyPullRequestsIntent = "my_pull_requests_intent"
 	defaultTrendingRepos = 5
 )
 
 // Create new types so we can make them conform to FulfillmentBuilder
-type GithubNotifications []*github.Notification
-type GithubIssues []*github.Issue
+type GithubNotifications struct {
+	notifications []*github.Notification
+	filter        NotificationFilter
+	skipped       int  // Lower priority notifications left out
+	more          bool // Whether there are unread notifications past the page fetched
+}
+type GithubIssues struct {
+	issues []*github.Issue
+	filter IssueFilter
+}
 
 type ProfileSummary struct {
-	user *github.User
+	user     *github.User
+	current  ProfileSnapshot
+	previous *ProfileSnapshot // Nil the first time the user asks
 }
 
 type FulfillmentReq struct {
@@ -39,11 +57,32 @@ type FulfillmentReq struct {
 type ResultReq struct {
 	Action     string        `json:"action,omitempty"`
 	Parameters ParametersReq `json:"parameters,omitempty"`
+	Contexts   []ContextReq  `json:"contexts,omitempty"`
+}
+
+type ContextReq struct {
+	Name       string                 `json:"name,omitempty"`
+	Parameters map[string]interface{} `json:"parameters,omitempty"`
 }
 
 type ParametersReq struct {
-	Number string `json:"number,omitempty"`
-	Lang   string `json:"lang,omitempty"`
+	Number    string `json:"number,omitempty"`
+	Lang      string `json:"lang,omitempty"`
+	Verbosity string `json:"verbosity,omitempty"`
+	Timezone  string `json:"timezone,omitempty"`
+	Repo      string `json:"repo,omitempty"`
+	Reason    string `json:"reason,omitempty"`
+	Type      string `json:"type,omitempty"`
+	Label     string `json:"label,omitempty"`
+	Milestone string `json:"milestone,omitempty"`
+	Sort      string `json:"sort,omitempty"`
+	Title     string `json:"title,omitempty"`
+	Body      string `json:"body,omitempty"`
+	Branch    string `json:"branch,omitempty"`
+	Org       string `json:"org,omitempty"`
+	Topic     string `json:"topic,omitempty"`
+	Stars     string `json:"stars,omitempty"`
+	Login     string `json:"login,omitempty"`
 }
 
 type OriginalReq struct {
@@ -59,6 +98,28 @@ type FulfillmentResp struct {
 	DisplayText string `json:"displayText,omitempty"`
 }
 
+// What the Assistant handler actually sends back. Kept separate from FulfillmentResp so
+// builders don't need to know about Dialogflow contexts.
+type AssistantResp struct {
+	*FulfillmentResp
+	ContextOut []FulfillmentContext `json:"contextOut,omitempty"`
+	Data       *AssistantData       `json:"data,omitempty"`
+}
+
+type FulfillmentContext struct {
+	Name       string            `json:"name"`
+	Lifespan   int               `json:"lifespan"`
+	Parameters map[string]string `json:"parameters,omitempty"`
+}
+
+type AssistantData struct {
+	Google AssistantGoogleData `json:"google"`
+}
+
+type AssistantGoogleData struct {
+	ExpectUserResponse bool `json:"expectUserResponse"`
+}
+
 type UserReq struct {
 	LastSeen    string `json:"lastSeen,omitempty"`
 	AccessToken string `json:"accessToken,omitempty"`
@@ -66,14 +127,187 @@ type UserReq struct {
 	UserId      string `json:"userId,omitempty"`
 }
 
-type Trending struct {
-	text, speech string
-}
-
 type FulfillmentBuilder interface {
 	buildFulfillment(ctx context.Context) *FulfillmentResp
 }
 
+// IntentRequest is the platform independent form of an intent invocation. Alexa, Assistant
+// and the command line client all translate their requests into one of these.
+type IntentRequest struct {
+	Name        string
+	AccessToken string
+	Number      string
+	Lang        string
+	Verbosity   string
+	Timezone    string
+	Repo        string        // Repository name as spoken, to narrow a report, notifications or issues
+	Reason      string        // Notification reasons as spoken, like "mentions"
+	Type        string        // Notification subject types, or issues versus pull requests, as spoken
+	Label       string        // Issue label, like "bug"
+	Milestone   string        // Issue milestone title
+	Sort        string        // Assigned issue order as spoken, like "oldest"
+	Title       string        // Title of an issue to create
+	Body        string        // Text of a comment
+	Branch      string        // Branch to report workflow runs on, the default branch if empty
+	Org         string        // Organization login as spoken
+	Topic       string        // What to search repos for, like "rate limiting"
+	Stars       string        // Minimum stars for a repo search, as spoken
+	Login       string        // Github user or org to look up, as spoken
+	UserID      string        // Prefixed with the platform, empty when there's no user to remember
+	Timeout     time.Duration // Deadline for slow upstream calls like trending
+	Session     *SessionState // Never nil, but empty on the first turn
+}
+
+var errUnknownIntent = errors.New("Incorrect fullfillment action")
+
+// Contexts created by the command line client carry one of these. We aren't running
+// inside App Engine there, so urlfetch, datastore and App Engine logging are unavailable.
+type cliContextKey struct{}
+
+type cliOptions struct {
+	logOutput io.Writer
+	prefs     *Preferences // From the config file, since there's no datastore
+}
+
+func withCLI(ctx context.Context, opts *cliOptions) context.Context {
+	return context.WithValue(ctx, cliContextKey{}, opts)
+}
+
+func cliFromContext(ctx context.Context) *cliOptions {
+	opts, _ := ctx.Value(cliContextKey{}).(*cliOptions)
+	return opts
+}
+
+// Log through App Engine, or to the configured writer when running from the command line.
+func debugf(ctx context.Context, format string, args ...interface{}) {
+	if opts := cliFromContext(ctx); opts != nil {
+		stdlog.New(opts.logOutput, "", stdlog.LstdFlags).Printf(format, args...)
+		return
+	}
+	log.Debugf(ctx, format, args...)
+}
+
+func httpTransport(ctx context.Context) http.RoundTripper {
+	if cliFromContext(ctx) != nil {
+		return http.DefaultTransport
+	}
+	return &urlfetch.Transport{Context: ctx}
+}
+
+func httpClient(ctx context.Context) *http.Client {
+	return &http.Client{Transport: httpTransport(ctx)}
+}
+
+// Attach the preferences of the user making req. Both fulfillIntent and building the
+// response need them.
+func userContext(ctx context.Context, req *IntentRequest) context.Context {
+	return withPreferences(ctx, loadPreferences(ctx, req.UserID))
+}
+
+// Run the intent named in req. Platform specific intents (like Alexa's help intent) must be
+// handled by the caller before getting here. ctx should carry the user's preferences, see
+// userContext.
+func fulfillIntent(ctx context.Context, req *IntentRequest) (FulfillmentBuilder, error) {
+	prefs := preferencesFromContext(ctx)
+	lang := req.Lang
+	if lang == "" {
+		lang = prefs.Lang
+	}
+
+	switch req.Name {
+	case SummaryIntent:
+		return getProfileSummary(ctx, req.AccessToken)
+	case TrendingReposIntent:
+		ctxWithDeadline, cancel := context.WithTimeout(ctx, req.Timeout) // This call sometimes takes a while
+		defer cancel()
+		client := httpClient(ctxWithDeadline)
+		if i, err := strconv.Atoi(req.Number); err == nil && i != 0 {
+			return getTrending(ctx, client, &i, extractLang(client, lang))
+		} else if prefs.ListLength > 0 {
+			return getTrending(ctx, client, &prefs.ListLength, extractLang(client, lang))
+		}
+		return getTrending(ctx, client, nil, extractLang(client, lang))
+	case SearchReposIntent:
+		ctxWithDeadline, cancel := context.WithTimeout(ctx, req.Timeout) // Resolving the language can be slow
+		defer cancel()
+		return searchRepos(ctxWithDeadline, httpClient(ctxWithDeadline), req)
+	case StarredReposIntent:
+		return getStarredRepos(ctx, req.AccessToken)
+	case GistsIntent:
+		return getGists(ctx, req.AccessToken)
+	case LookupUserIntent:
+		return lookupUser(ctx, req.AccessToken, req.Login)
+	case LookupRepoIntent:
+		return lookupRepo(ctx, req.AccessToken, req.Repo)
+	case NotificationsIntent:
+		filter, err := notificationFilter(ctx, req)
+		if err != nil {
+			return &SpokenResponse{err.Error(), err.Error(), nil}, nil
+		}
+		return getNotifications(ctx, req.AccessToken, filter, priorityScorer(prefs.PriorityRepos))
+	case AssignedIssuesIntent:
+		filter, err := issueFilter(req)
+		if err != nil {
+			return &SpokenResponse{err.Error(), err.Error(), nil}, nil
+		}
+		return getAssignedIssues(ctx, req.AccessToken, filter)
+	case ReviewRequestsIntent:
+		return getReviewRequests(ctx, req.AccessToken)
+	case MyPullRequestsIntent:
+		return getMyPullRequests(ctx, req.AccessToken)
+	case RepoReportIntent:
+		return getRepoReport(ctx, req.AccessToken, req.Repo)
+	case CreateIssueIntent:
+		return draftIssue(ctx, req.AccessToken, req)
+	case CommentIntent:
+		return commentOnIssue(ctx, req.AccessToken, req.Session, req.Number, req.Body)
+	case CloseIssueIntent:
+		return confirmCloseIssue(req.Session, req.Number), nil
+	case ReopenIssueIntent:
+		return reopenIssue(ctx, req.AccessToken, req.Session, req.Number)
+	case OrgDigestIntent:
+		ctxWithDeadline, cancel := context.WithTimeout(ctx, req.Timeout) // The digest makes a lot of calls
+		defer cancel()
+		return getOrgDigest(ctxWithDeadline, req)
+	case ActivityIntent:
+		return getActivity(ctx, req.AccessToken)
+	case SecurityAlertsIntent:
+		return getSecurityAlerts(ctx, req.AccessToken)
+	case ReleasesIntent:
+		return getReleases(ctx, req.AccessToken, req.UserID)
+	case WorkflowRunsIntent:
+		return getWorkflowRuns(ctx, req.AccessToken, req.Repo, req.Branch)
+	case RerunIntent:
+		return confirmRerun(req.Session), nil
+	case StarIntent, UnstarIntent, WatchIntent:
+		return repoAction(ctx, req)
+	case MarkAllReadIntent:
+		return confirmMarkAllRead(req.Session), nil
+	case MarkReadIntent:
+		return markNotificationRead(ctx, req.AccessToken, req.Session, req.Number)
+	case YesIntent, NoIntent:
+		return confirmPendingAction(ctx, req, req.Name == YesIntent)
+	case DetailsIntent:
+		return getDetails(ctx, req.AccessToken, req.Session, req.Number)
+	case NextIntent:
+		return pageList(req.Session, 1), nil
+	case PreviousIntent:
+		return pageList(req.Session, -1), nil
+	case BriefingIntent:
+		ctxWithDeadline, cancel := context.WithTimeout(ctx, req.Timeout) // Every section shares one deadline
+		defer cancel()
+		return getBriefing(ctxWithDeadline, req.AccessToken, req.UserID, httpClient(ctxWithDeadline), lang)
+	case SetLanguageIntent, SetListLengthIntent, SetVerbosityIntent, SetTimezoneIntent, SetNotificationFilterIntent, SetPriorityRepoIntent, SetOrgIntent:
+		ctxWithDeadline, cancel := context.WithTimeout(ctx, req.Timeout) // Checking the language can be slow
+		defer cancel()
+		return setPreference(ctx, req, httpClient(ctxWithDeadline))
+	case PreferencesIntent:
+		return describePreferences(ctx), nil
+	default:
+		return nil, errUnknownIntent
+	}
+}
+
 func minInt(x, y int) int {
 	if x < y {
 		return x
@@ -81,42 +315,73 @@ func minInt(x, y int) int {
 	return y
 }
 
+// Describe how long ago then was in words, like "yesterday" or "3 weeks ago".
+func timeAgo(then, now time.Time) string {
+	days := int(now.Sub(then).Hours() / 24)
+	switch {
+	case days <= 0:
+		return "today"
+	case days == 1:
+		return "yesterday"
+	case days < 14:
+		return fmt.Sprintf("%d days ago", days)
+	case days < 60:
+		return fmt.Sprintf("%d weeks ago", days/7)
+	case days < 730:
+		return fmt.Sprintf("%d months ago", days/30)
+	default:
+		return fmt.Sprintf("%d years ago", days/365)
+	}
+}
+
 func extractLang(client *http.Client, lang string) string {
+	_, urlName := resolveTrendingLang(client, lang)
+	return urlName
+}
+
+// Find a language the trending page knows by name. Returns its display name, like "C#", and
+// its URL name, like "c%23", or empty strings if there's no such language.
+func resolveTrendingLang(client *http.Client, lang string) (name, urlName string) {
 	trend := trending.NewTrendingWithClient(client)
 	langs, err := trend.GetLanguages()
 	if err != nil {
-		return ""
+		return "", ""
 	}
 
 	if lang == "" {
-		return ""
+		return "", ""
 	}
 
 	for _, trendLang := range langs {
 		if strings.ToLower(trendLang.Name) == strings.ToLower(lang) {
-			return trendLang.URLName
+			return trendLang.Name, trendLang.URLName
 		}
 	}
 
-	return ""
+	return "", ""
 }
 
 func debug(ctx context.Context, data []byte, err error) {
 	if err == nil {
-		log.Debugf(ctx, "Request: %s", string(data))
+		debugf(ctx, "Request: %s", string(data))
 	} else {
-		log.Debugf(ctx, err.Error())
+		debugf(ctx, err.Error())
 	}
 }
 
 // Count may be nil if the user didn't specify how many. Give them the default value.
 func getTrending(ctx context.Context, client *http.Client, count *int, lang string) (FulfillmentBuilder, error) {
-	projects, err := get(ctx, lang)
+	var projects TrendingProjects
+	var err error
+	if cliFromContext(ctx) != nil {
+		projects, err = fetchTrending(client, lang) // No datastore cache outside App Engine
+	} else {
+		projects, err = get(ctx, lang)
+	}
 	if err != nil {
 		return nil, err
 	}
 
-	var projectText, projectSpeech string
 	var maxTrending int
 	if count == nil {
 		maxTrending = defaultTrendingRepos
@@ -124,24 +389,40 @@ func getTrending(ctx context.Context, client *http.Client, count *int, lang stri
 		maxTrending = *count
 	}
 
+	list := newPagedList(ctx, TrendingReposIntent)
+	list.Empty = "I couldn't find any trending repositories."
 	if lang != "" {
-		projectSpeech = fmt.Sprintf("<p>Here are the top %d trending repositories for %s:</p>", minInt(len(projects.Data), maxTrending), lang)
+		list.SpeechHeader = fmt.Sprintf("<p>Here are the top %d trending repositories for %s:</p>", minInt(len(projects.Data), maxTrending), lang)
 	} else {
-		projectSpeech = fmt.Sprintf("<p>Here are the top %d trending repositories:</p>", minInt(len(projects.Data), maxTrending))
+		list.SpeechHeader = fmt.Sprintf("<p>Here are the top %d trending repositories:</p>", minInt(len(projects.Data), maxTrending))
 	}
 
 	for index, project := range projects.Data {
 		if index >= maxTrending {
 			break
 		}
-		projectSpeech += fmt.Sprintf("<p>#%d. %s by %s: %s</p>", index+1, project.RepositoryName, project.Owner, project.Description)
-		projectText += fmt.Sprintf("\n#%d. %s by %s: %s", index+1, project.RepositoryName, project.Owner, project.Description)
+		item := ListItem{
+			Text:   fmt.Sprintf("#%d. %s by %s: %s", index+1, project.RepositoryName, project.Owner, project.Description),
+			Speech: fmt.Sprintf("<p>#%d. %s by %s: %s</p>", index+1, project.RepositoryName, project.Owner, project.Description),
+			Ref:    project.Owner + "/" + project.RepositoryName,
+		}
+		if preferencesFromContext(ctx).brief() {
+			item.Speech = fmt.Sprintf("<p>#%d. %s by %s</p>", index+1, project.RepositoryName, project.Owner)
+		}
+		list.add(item)
 	}
 
-	return &Trending{projectText, projectSpeech}, nil
+	return list, nil
 }
 
 func (sum *ProfileSummary) buildFulfillment(ctx context.Context) *FulfillmentResp {
+	if changes := sum.changes(ctx); changes != "" {
+		summary := fmt.Sprintf("Hello %s. %s", sum.user.GetName(), changes)
+		debugf(ctx, "Built fulfillment with string: %s", summary)
+		return &FulfillmentResp{Speech: "<speak>" + summary + "</speak>", DisplayText: summary}
+	}
+
+	// First time, so there's nothing to compare against
 	summary := fmt.Sprintf(
 		"Hello %s. You currently have %d public repos, "+
 			"%d private repos, and you own %d of these private repos."+
@@ -149,91 +430,124 @@ func (sum *ProfileSummary) buildFulfillment(ctx context.Context) *FulfillmentRes
 		sum.user.GetName(), sum.user.GetPublicRepos(), sum.user.GetTotalPrivateRepos(),
 		sum.user.GetOwnedPrivateRepos(), sum.user.GetFollowers(), sum.user.GetFollowing())
 	resp := &FulfillmentResp{Speech: "<speak>" + summary + "</speak>", DisplayText: summary}
-	log.Debugf(ctx, "Built fulfillment with string: %s", summary)
+	debugf(ctx, "Built fulfillment with string: %s", summary)
 	return resp
 }
 
-func (trending *Trending) buildFulfillment(ctx context.Context) *FulfillmentResp {
-	resp := &FulfillmentResp{Speech: "<speak>" + trending.speech + "</speak>", DisplayText: trending.text}
-	log.Debugf(ctx, "Built fulfillment with string: %s", trending.speech)
-	return resp
+func (not *GithubNotifications) toList(ctx context.Context) *PagedList {
+	list := newPagedList(ctx, NotificationsIntent)
+	list.SpeechHeader = fmt.Sprintf("<p>Here are your unread %s, most important first:</p>", not.filter.describe())
+	if not.skipped > 0 {
+		skipped := fmt.Sprintf("I skipped %s.", pluralize(not.skipped, "lower priority notification", "lower priority notifications"))
+		list.SpeechHeader += "<p>" + skipped + "</p>"
+		list.TextHeader = skipped
+	}
+	list.Empty = fmt.Sprintf("You have no unread %s", not.filter.describe())
+	list.Hint = "<p>You can say mark them all as read, or mark a number as read.</p>"
+
+	prefs := preferencesFromContext(ctx)
+	now := time.Now()
+	for i, notification := range not.notifications {
+		description := describeNotification(notification, now, prefs.location(), prefs.brief())
+		list.add(ListItem{
+			Text:   fmt.Sprintf("#%d: %s", i+1, description),
+			Speech: fmt.Sprintf("<p>#%d: %s</p>", i+1, description),
+			Ref:    notification.GetID(),
+		})
+	}
+	return list
 }
 
 func (not *GithubNotifications) buildFulfillment(ctx context.Context) *FulfillmentResp {
-	var text, speech string
-	if len([]*github.Notification(*not)) > 0 {
-		speech = "<speak><p>Here are your unread notifications:</p>"
-	} else {
-		speech = "<speak>You have no unread notifications"
+	return not.toList(ctx).buildFulfillment(ctx)
+}
+
+// Remember when the notifications were read so "mark them all as read" doesn't touch newer ones.
+func (not *GithubNotifications) sessionState(ctx context.Context) *SessionState {
+	state := not.toList(ctx).sessionState(ctx)
+	if state != nil {
+		state.NotificationsReadAt = time.Now()
+		state.NotificationsPartial = not.partial()
 	}
+	return state
+}
+
+// Whether some unread notifications were left out, by the filter, the priority cap, or paging.
+func (not *GithubNotifications) partial() bool {
+	return !not.filter.empty() || not.skipped > 0 || not.more || len(not.notifications) > maxListItems
+}
 
-	for i, notification := range []*github.Notification(*not) {
-		text += fmt.Sprintf("\n#%d: This notification is on an %s and says: %s", i+1, notification.Subject.GetType(), notification.Subject.GetTitle())
-		speech += fmt.Sprintf("<p>#%d: This notification is on an %s and says: %s</p>", i+1, notification.Subject.GetType(), notification.Subject.GetTitle())
+func (iss *GithubIssues) toList(ctx context.Context) *PagedList {
+	list := newPagedList(ctx, AssignedIssuesIntent)
+	list.SpeechHeader = fmt.Sprintf("<p>Here are the open %s:</p>", iss.filter.describe())
+	if iss.filter.Sort != nil {
+		list.SpeechHeader = fmt.Sprintf("<p>Here are the open %s, %s:</p>", iss.filter.describe(), iss.filter.Sort.Spoken)
+	}
+	list.Empty = fmt.Sprintf("You have no open %s.", iss.filter.describe())
+	loc := preferencesFromContext(ctx).location()
+
+	for i, issue := range iss.issues {
+		list.add(ListItem{
+			Text:   fmt.Sprintf("#%d: Opened in %s on %s by %s: %s", i+1, issue.Repository.GetName(), issue.GetCreatedAt().In(loc).Format("Monday, January 2"), issue.User.GetLogin(), issue.GetTitle()),
+			Speech: fmt.Sprintf("<p>#%d: Opened in %s on %s by %s: %s</p>", i+1, issue.Repository.GetName(), issue.GetCreatedAt().In(loc).Format("Monday, January 2"), issue.User.GetLogin(), issue.GetTitle()),
+			Ref:    issueRef(issue.Repository.GetFullName(), issue.GetNumber()),
+		})
 	}
-	return &FulfillmentResp{speech + "</speak>", text}
+	return list
 }
 
 func (iss *GithubIssues) buildFulfillment(ctx context.Context) *FulfillmentResp {
-	var text, speech string
-	if len([]*github.Issue(*iss)) > 0 {
-		speech = fmt.Sprintf("<speak><p>Here are the open issues assigned to you:</p>")
-	} else {
-		speech = fmt.Sprintf("<speak>You have no open issues assigned to you.")
-	}
+	return iss.toList(ctx).buildFulfillment(ctx)
+}
 
-	for i, issue := range []*github.Issue(*iss) {
-		text += fmt.Sprintf("\n#%d: Opened in %s on %s by %s: %s", i+1, issue.Repository.GetName(), issue.GetCreatedAt().Format("Monday, January 2"), issue.User.GetLogin(), issue.GetTitle())
-		speech += fmt.Sprintf("<p>#%d: Opened in %s on %s by %s: %s</p>", i+1, issue.Repository.GetName(), issue.GetCreatedAt().Format("Monday, January 2"), issue.User.GetLogin(), issue.GetTitle())
-	}
-	return &FulfillmentResp{speech + "</speak>", text}
+func (iss *GithubIssues) sessionState(ctx context.Context) *SessionState {
+	return iss.toList(ctx).sessionState(ctx)
 }
 
-func getNotifications(ctx context.Context, accessToken string) (FulfillmentBuilder, error) {
+func getNotifications(ctx context.Context, accessToken string, filter NotificationFilter, scorer NotificationScorer) (FulfillmentBuilder, error) {
 	client := createGithubClient(ctx, accessToken)
-	notifications, _, err := client.Activity.ListNotifications(ctx, nil)
+	notifications, resp, err := client.Activity.ListNotifications(ctx, filter.listOptions())
 
 	if err != nil {
 		return nil, err
 	}
 
-	ghNot := GithubNotifications(notifications)
-	return &ghNot, nil
+	ranked, skipped := rankNotifications(filter.apply(notifications), scorer, time.Now(), maxRankedNotifications)
+	return &GithubNotifications{ranked, filter, skipped, resp.NextPage != 0}, nil
 }
 
-func getAssignedIssues(ctx context.Context, accessToken string) (FulfillmentBuilder, error) {
+func getAssignedIssues(ctx context.Context, accessToken string, filter IssueFilter) (FulfillmentBuilder, error) {
 	client := createGithubClient(ctx, accessToken)
-	issues, _, err := client.Issues.List(ctx, true, nil)
+	issues, _, err := client.Issues.List(ctx, true, filter.listOptions())
 
 	if err != nil {
 		return nil, err
 	}
 
-	iss := GithubIssues(issues)
-	return &iss, nil
+	return &GithubIssues{filter.apply(issues), filter}, nil
 }
 
 func getProfileSummary(ctx context.Context, accessToken string) (FulfillmentBuilder, error) {
 	client := createGithubClient(ctx, accessToken)
-	user, _, err := client.Users.Get(ctx, "") // Get authenticated user
+	return fetchProfileSummary(ctx, client)
+}
 
-	if err != nil {
-		return nil, err
+func createGithubClient(ctx context.Context, accessToken string) *github.Client {
+	if accessToken == "" {
+		return github.NewClient(httpClient(ctx)) // Anonymous, which is enough for public data
 	}
 
-	summary := &ProfileSummary{user} // Type conversion to custom type so we can use buildFulfillment method
-
-	return summary, nil
+	client := github.NewClient(authHTTPClient(ctx, accessToken))
+	return client
 }
 
-func createGithubClient(ctx context.Context, accessToken string) *github.Client {
+// An HTTP client that sends the user's token with every request, for both the REST and GraphQL APIs.
+func authHTTPClient(ctx context.Context, accessToken string) *http.Client {
 	ts := oauth2.StaticTokenSource(&oauth2.Token{AccessToken: accessToken})
-	authClient := &http.Client{
+	return &http.Client{
 		Transport: &oauth2.Transport{
 			Source: oauth2.ReuseTokenSource(nil, ts),
-			Base:   &urlfetch.Transport{Context: ctx},
+			Base:   httpTransport(ctx),
 		},
 	}
-	client := github.NewClient(authClient)
-	return client
 }
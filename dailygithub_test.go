@@ -4,6 +4,7 @@ import (
 	"net/http"
 	_ "net/http/pprof"
 	"testing"
+	"time"
 )
 
 func Test_extractLang(t *testing.T) {
@@ -52,3 +53,27 @@ func Test_minInt(t *testing.T) {
 		})
 	}
 }
+
+func Test_timeAgo(t *testing.T) {
+	now := time.Date(2018, time.March, 10, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		then time.Time
+		want string
+	}{
+		{"Earlier today", now.Add(-3 * time.Hour), "today"},
+		{"In the future", now.Add(time.Hour), "today"},
+		{"Yesterday", now.Add(-30 * time.Hour), "yesterday"},
+		{"Days", now.AddDate(0, 0, -5), "5 days ago"},
+		{"Weeks", now.AddDate(0, 0, -21), "3 weeks ago"},
+		{"Months", now.AddDate(0, -4, 0), "4 months ago"},
+		{"Years", now.AddDate(-3, 0, 0), "3 years ago"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := timeAgo(tt.then, now); got != tt.want {
+				t.Errorf("timeAgo() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,284 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/github"
+)
+
+const (
+	DetailsIntent = "details_intent"
+
+	summaryLength   = 300 // Characters of an issue body or README to read out
+	stargazerPages  = 3   // How far back to look when counting new stars
+	stargazersLimit = 100
+)
+
+var (
+	markdownImage    = regexp.MustCompile(`!\[[^\]]*\]\([^)]*\)`)
+	markdownLink     = regexp.MustCompile(`\[([^\]]*)\]\([^)]*\)`)
+	htmlTag          = regexp.MustCompile(`<[^>]*>`)
+	markdownEmphasis = regexp.MustCompile("[*_`]+")
+)
+
+// Pull the first real paragraph of prose out of markdown, skipping headings, badges,
+// images and code blocks, and strip the formatting so it can be read aloud.
+func firstParagraph(markdown string) string {
+	var paragraph []string
+	inCode := false
+	for _, line := range strings.Split(markdown, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "```") {
+			inCode = !inCode
+			continue
+		}
+		if inCode {
+			continue
+		}
+
+		line = cleanMarkdownLine(line)
+		isBreak := line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "---") || strings.HasPrefix(line, "===")
+		if isBreak {
+			if len(paragraph) > 0 {
+				break
+			}
+			continue
+		}
+		paragraph = append(paragraph, line)
+	}
+	return strings.Join(paragraph, " ")
+}
+
+// The first line of prose in markdown, like the first bullet of release notes.
+func firstLine(markdown string) string {
+	inCode := false
+	for _, line := range strings.Split(markdown, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "```") {
+			inCode = !inCode
+			continue
+		}
+		if inCode || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "---") || strings.HasPrefix(line, "===") {
+			continue
+		}
+
+		if line = strings.TrimSpace(strings.TrimLeft(cleanMarkdownLine(line), "-+")); line != "" {
+			return line
+		}
+	}
+	return ""
+}
+
+// Strip images, links, tags and emphasis from a line of markdown.
+func cleanMarkdownLine(line string) string {
+	line = markdownImage.ReplaceAllString(line, "")
+	line = markdownLink.ReplaceAllString(line, "$1")
+	line = htmlTag.ReplaceAllString(line, "")
+	line = markdownEmphasis.ReplaceAllString(line, "")
+	return strings.TrimSpace(strings.TrimLeft(line, ">"))
+}
+
+// Shorten text to about max characters, cutting at a word boundary.
+func truncateWords(text string, max int) string {
+	if len(text) <= max {
+		return text
+	}
+
+	cut := strings.LastIndex(text[:max], " ")
+	if cut <= 0 {
+		cut = max
+	}
+	return strings.TrimRight(text[:cut], ",.;:") + "..."
+}
+
+func summarize(markdown string) string {
+	return truncateWords(firstParagraph(markdown), summaryLength)
+}
+
+// Describe the item the user picked from the list just read out.
+func getDetails(ctx context.Context, accessToken string, session *SessionState, number string) (FulfillmentBuilder, error) {
+	item, err := resolveOrdinal(session, number)
+	if err != nil {
+		return &SpokenResponse{err.Error(), err.Error(), session}, nil
+	}
+
+	client := createGithubClient(ctx, accessToken)
+	var text, speech string
+	switch session.List.Kind {
+	case TrendingReposIntent, SearchReposIntent, StarredReposIntent, RepoReportIntent, ReleasesIntent:
+		text, speech, err = repoDetails(ctx, client, item.Ref)
+	case GistsIntent:
+		text, speech, err = gistDetails(ctx, client, item.Ref)
+	case NotificationsIntent:
+		text, speech, err = notificationDetails(ctx, client, item.Ref)
+	default: // Everything else is a list of issues or pull requests
+		text, speech, err = issueDetails(ctx, client, item.Ref)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	state := *session
+	state.PendingAction = ""
+	if _, _, _, ok := parseIssueRef(item.Ref); ok {
+		state.Focus = item.Ref
+	}
+	return &SpokenResponse{text, speech, &state}, nil
+}
+
+// Count stars added since a time by walking back from the newest stargazers.
+func starsSince(ctx context.Context, client *github.Client, owner, repo string, since time.Time) (int, error) {
+	opt := &github.ListOptions{PerPage: stargazersLimit}
+	_, resp, err := client.Activity.ListStargazers(ctx, owner, repo, opt)
+	if err != nil {
+		return 0, err
+	}
+
+	page := resp.LastPage
+	if page == 0 {
+		page = 1 // Only one page
+	}
+
+	count := 0
+	for i := 0; i < stargazerPages && page > 0; i, page = i+1, page-1 {
+		opt.Page = page
+		stargazers, _, err := client.Activity.ListStargazers(ctx, owner, repo, opt)
+		if err != nil {
+			return count, err
+		}
+
+		for j := len(stargazers) - 1; j >= 0; j-- {
+			if stargazers[j].GetStarredAt().Before(since) {
+				return count, nil
+			}
+			count++
+		}
+	}
+	return count, nil
+}
+
+func repoDetails(ctx context.Context, client *github.Client, ref string) (text, speech string, err error) {
+	owner, name, ok := parseRepoRef(ref)
+	if !ok {
+		return "", "", fmt.Errorf("bad repository reference %q", ref)
+	}
+
+	repo, _, err := client.Repositories.Get(ctx, owner, name)
+	if err != nil {
+		return "", "", err
+	}
+
+	text = fmt.Sprintf("%s has %d stars and %d forks", repo.GetFullName(), repo.GetStargazersCount(), repo.GetForksCount())
+	if repo.GetLanguage() != "" {
+		text += fmt.Sprintf(", and is written in %s", repo.GetLanguage())
+	}
+	text += "."
+
+	if today, err := starsSince(ctx, client, owner, name, time.Now().Add(-24*time.Hour)); err != nil {
+		debugf(ctx, "Failed to count today's stars for %s: %v", ref, err)
+	} else if today > 0 {
+		text += fmt.Sprintf(" It gained %d stars today.", today)
+	}
+
+	readme, _, err := client.Repositories.GetReadme(ctx, owner, name, nil)
+	if err == nil {
+		if content, err := readme.GetContent(); err == nil {
+			if summary := summarize(content); summary != "" {
+				text += " The readme says: " + summary
+			}
+		}
+	}
+
+	return text + "\n" + repo.GetHTMLURL(), "<p>" + text + "</p>", nil
+}
+
+func issueDetails(ctx context.Context, client *github.Client, ref string) (text, speech string, err error) {
+	owner, repo, number, ok := parseIssueRef(ref)
+	if !ok {
+		return "", "", fmt.Errorf("bad issue reference %q", ref)
+	}
+
+	issue, _, err := client.Issues.Get(ctx, owner, repo, number)
+	if err != nil {
+		return "", "", err
+	}
+
+	var comment *github.IssueComment
+	if issue.GetComments() > 0 {
+		// One comment per page makes the last page the latest comment
+		opt := &github.IssueListCommentsOptions{ListOptions: github.ListOptions{PerPage: 1, Page: issue.GetComments()}}
+		comments, _, err := client.Issues.ListComments(ctx, owner, repo, number, opt)
+		if err != nil {
+			debugf(ctx, "Failed to get latest comment on %s: %v", ref, err)
+		} else if len(comments) > 0 {
+			comment = comments[0]
+		}
+	}
+
+	text, speech = describeIssue(issue.GetTitle(), issue.User.GetLogin(), issue.GetBody(), comment)
+	return text + "\n" + issue.GetHTMLURL(), speech, nil
+}
+
+func describeIssue(title, author, body string, comment *github.IssueComment) (text, speech string) {
+	text = fmt.Sprintf("%s, opened by %s.", title, author)
+	if summary := summarize(body); summary != "" {
+		text += " It says: " + summary
+	}
+	speech = "<p>" + text + "</p>"
+
+	if comment != nil {
+		latest := fmt.Sprintf("The latest comment is from %s: %s", comment.User.GetLogin(), summarize(comment.GetBody()))
+		text += "\n" + latest
+		speech += "<p>" + latest + "</p>"
+	}
+	return text, speech
+}
+
+// Notifications point at an issue, pull request or release through its API URL.
+func notificationDetails(ctx context.Context, client *github.Client, threadID string) (text, speech string, err error) {
+	thread, _, err := client.Activity.GetThread(ctx, threadID)
+	if err != nil {
+		return "", "", err
+	}
+
+	subject := thread.GetSubject()
+	switch subject.GetType() {
+	case "Issue", "PullRequest":
+	default:
+		text = fmt.Sprintf("This is a %s in %s: %s", subject.GetType(), thread.Repository.GetFullName(), subject.GetTitle())
+		return text, "<p>" + text + "</p>", nil
+	}
+
+	var issue github.Issue // Pull requests decode fine as issues for what we need
+	if err := getAPIURL(ctx, client, subject.GetURL(), &issue); err != nil {
+		return "", "", err
+	}
+
+	var comment *github.IssueComment
+	if url := subject.GetLatestCommentURL(); url != "" && url != subject.GetURL() {
+		comment = &github.IssueComment{}
+		if err := getAPIURL(ctx, client, url, comment); err != nil {
+			debugf(ctx, "Failed to get latest comment for thread %s: %v", threadID, err)
+			comment = nil
+		}
+	}
+
+	text, speech = describeIssue(issue.GetTitle(), issue.User.GetLogin(), issue.GetBody(), comment)
+	return text + "\n" + issue.GetHTMLURL(), speech, nil
+}
+
+// Fetch an API URL into v. It can be relative to the API root, or a full URL like the ones
+// notifications hand out.
+func getAPIURL(ctx context.Context, client *github.Client, url string, v interface{}) error {
+	req, err := client.NewRequest("GET", url, nil)
+	if err != nil {
+		return err
+	}
+	_, err = client.Do(ctx, req, v)
+	return err
+}
@@ -0,0 +1,88 @@
+package main
+
+import "testing"
+
+func Test_firstParagraph(t *testing.T) {
+	tests := []struct {
+		name     string
+		markdown string
+		want     string
+	}{
+		{"Plain text", "Hello there.\nSecond line.\n\nNext paragraph.", "Hello there. Second line."},
+		{"Skips heading and badges", "# Project\n[![Build](https://x/y.svg)](https://x)\n\nA *fast* tool for [things](https://t).", "A fast tool for things."},
+		{"Skips code", "```\ngo get x\n```\nUse it well.", "Use it well."},
+		{"Strips html", "<p align=\"center\"><img src=\"logo.png\"></p>\n\nThe `best` library.", "The best library."},
+		{"Empty", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := firstParagraph(tt.markdown); got != tt.want {
+				t.Errorf("firstParagraph() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_truncateWords(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		max  int
+		want string
+	}{
+		{"Short enough", "hello world", 20, "hello world"},
+		{"Cuts at a word", "hello there, world", 14, "hello there..."},
+		{"No spaces", "abcdefghij", 4, "abcd..."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := truncateWords(tt.text, tt.max); got != tt.want {
+				t.Errorf("truncateWords() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_parseIssueRef(t *testing.T) {
+	tests := []struct {
+		name   string
+		ref    string
+		owner  string
+		repo   string
+		number int
+		ok     bool
+	}{
+		{"Valid", "golang/go#123", "golang", "go", 123, true},
+		{"No number", "golang/go", "", "", 0, false},
+		{"Bad number", "golang/go#abc", "", "", 0, false},
+		{"No owner", "go#1", "", "", 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			owner, repo, number, ok := parseIssueRef(tt.ref)
+			if owner != tt.owner || repo != tt.repo || number != tt.number || ok != tt.ok {
+				t.Errorf("parseIssueRef() = %v, %v, %v, %v, want %v, %v, %v, %v", owner, repo, number, ok, tt.owner, tt.repo, tt.number, tt.ok)
+			}
+		})
+	}
+}
+
+func Test_firstLine(t *testing.T) {
+	tests := []struct {
+		name     string
+		markdown string
+		want     string
+	}{
+		{"Bullets", "## What's Changed\n* Fix the **scheduler** by @ada in [#12](https://github.com/a/b/pull/12)\n* Add docs", "Fix the scheduler by @ada in #12"},
+		{"Dashes", "- Faster startup\n- Smaller binary", "Faster startup"},
+		{"Code first", "```\ngo get foo\n```\nInstall with go get.", "Install with go get."},
+		{"Empty", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := firstLine(tt.markdown); got != tt.want {
+				t.Errorf("firstLine() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
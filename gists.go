@@ -0,0 +1,153 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/github"
+)
+
+const (
+	StarredReposIntent = "starred_repos_intent"
+	GistsIntent        = "gists_intent"
+)
+
+// The user's most recently starred repos, newest first
+type GithubStarred []*github.StarredRepository
+
+// The user's most recently updated gists
+type GithubGists []*github.Gist
+
+func getStarredRepos(ctx context.Context, accessToken string) (FulfillmentBuilder, error) {
+	client := createGithubClient(ctx, accessToken)
+	opt := &github.ActivityListStarredOptions{Sort: "created", Direction: "desc", ListOptions: github.ListOptions{PerPage: maxListItems}}
+	starred, _, err := client.Activity.ListStarred(ctx, "", opt)
+	if err != nil {
+		return nil, err
+	}
+
+	list := GithubStarred(starred)
+	return &list, nil
+}
+
+func (starred *GithubStarred) toList(ctx context.Context) *PagedList {
+	list := newPagedList(ctx, StarredReposIntent)
+	list.Empty = "You haven't starred any repos yet."
+	list.SpeechHeader = "<p>Here are the repos you starred most recently:</p>"
+	list.Hint = "<p>You can say tell me more about number 1.</p>"
+
+	now, loc := time.Now(), preferencesFromContext(ctx).location()
+	for i, star := range *starred {
+		repo := star.GetRepository()
+		when := relativeTime(star.GetStarredAt().Time, now, loc)
+		item := ListItem{
+			Text:   fmt.Sprintf("#%d. %s, starred %s: %s\n%s", i+1, repo.GetFullName(), when, repo.GetDescription(), repo.GetHTMLURL()),
+			Speech: fmt.Sprintf("<p>#%d. %s by %s, starred %s: %s</p>", i+1, repo.GetName(), repo.GetOwner().GetLogin(), when, repo.GetDescription()),
+			Ref:    repo.GetFullName(),
+		}
+		if preferencesFromContext(ctx).brief() {
+			item.Speech = fmt.Sprintf("<p>#%d. %s, starred %s</p>", i+1, repo.GetName(), when)
+		}
+		list.add(item)
+	}
+	return list
+}
+
+func (starred *GithubStarred) buildFulfillment(ctx context.Context) *FulfillmentResp {
+	return starred.toList(ctx).buildFulfillment(ctx)
+}
+
+func (starred *GithubStarred) sessionState(ctx context.Context) *SessionState {
+	return starred.toList(ctx).sessionState(ctx)
+}
+
+func getGists(ctx context.Context, accessToken string) (FulfillmentBuilder, error) {
+	client := createGithubClient(ctx, accessToken)
+	gists, _, err := client.Gists.List(ctx, "", &github.GistListOptions{ListOptions: github.ListOptions{PerPage: maxListItems}})
+	if err != nil {
+		return nil, err
+	}
+
+	list := GithubGists(gists)
+	return &list, nil
+}
+
+// A gist's file names in order, since the API hands them back as a map.
+func gistFileNames(gist *github.Gist) []string {
+	var names []string
+	for name := range gist.Files {
+		names = append(names, string(name))
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Describe a gist, like "notes.md and todo.txt, updated yesterday: Meeting notes". Gists
+// without a description are described by their files alone.
+func describeGist(gist *github.Gist, now time.Time, loc *time.Location) string {
+	files := gistFileNames(gist)
+	if len(files) > 3 {
+		files = append(files[:2], pluralize(len(files)-2, "other file", "other files"))
+	}
+	description := fmt.Sprintf("%s, updated %s", joinWords(files), relativeTime(gist.GetUpdatedAt(), now, loc))
+	if !gist.GetPublic() {
+		description = "Secret gist " + description
+	}
+	if text := strings.TrimSpace(gist.GetDescription()); text != "" {
+		description += ": " + text
+	}
+	return description
+}
+
+func (gists *GithubGists) toList(ctx context.Context) *PagedList {
+	list := newPagedList(ctx, GistsIntent)
+	list.Empty = "You don't have any gists yet."
+	list.SpeechHeader = "<p>Here are your latest gists:</p>"
+
+	now, loc := time.Now(), preferencesFromContext(ctx).location()
+	for i, gist := range *gists {
+		description := describeGist(gist, now, loc)
+		list.add(ListItem{
+			Text:   fmt.Sprintf("#%d. %s\n%s", i+1, description, gist.GetHTMLURL()),
+			Speech: fmt.Sprintf("<p>#%d. %s</p>", i+1, description),
+			Ref:    gist.GetID(),
+		})
+	}
+	return list
+}
+
+func (gists *GithubGists) buildFulfillment(ctx context.Context) *FulfillmentResp {
+	return gists.toList(ctx).buildFulfillment(ctx)
+}
+
+func (gists *GithubGists) sessionState(ctx context.Context) *SessionState {
+	return gists.toList(ctx).sessionState(ctx)
+}
+
+// Read the start of a gist's first file.
+func gistDetails(ctx context.Context, client *github.Client, id string) (text, speech string, err error) {
+	gist, _, err := client.Gists.Get(ctx, id)
+	if err != nil {
+		return "", "", err
+	}
+
+	names := gistFileNames(gist)
+	if len(names) == 0 {
+		text = "This gist is empty."
+		return text + "\n" + gist.GetHTMLURL(), "<p>" + text + "</p>", nil
+	}
+
+	file := gist.Files[github.GistFilename(names[0])]
+	text = fmt.Sprintf("%s is %s", names[0], pluralize(strings.Count(file.GetContent(), "\n")+1, "line", "lines"))
+	if file.GetLanguage() != "" {
+		text += " of " + file.GetLanguage()
+	}
+	text += "."
+	if start := truncateWords(firstLine(file.GetContent()), summaryLength); start != "" {
+		text += " It starts: " + start
+	}
+	return text + "\n" + gist.GetHTMLURL(), "<p>" + text + "</p>", nil
+}
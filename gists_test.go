@@ -0,0 +1,36 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-github/github"
+)
+
+func Test_describeGist(t *testing.T) {
+	now := time.Date(2018, 3, 10, 12, 0, 0, 0, time.UTC)
+	updated := now.Add(-3 * time.Hour)
+	gist := func(public bool, description string, names ...string) *github.Gist {
+		files := map[github.GistFilename]github.GistFile{}
+		for _, name := range names {
+			files[github.GistFilename(name)] = github.GistFile{Filename: github.String(name)}
+		}
+		return &github.Gist{Public: github.Bool(public), Description: github.String(description), Files: files, UpdatedAt: &updated}
+	}
+	tests := []struct {
+		name string
+		gist *github.Gist
+		want string
+	}{
+		{"Described", gist(true, "Meeting notes", "todo.txt", "notes.md"), "notes.md and todo.txt, updated 3 hours ago: Meeting notes"},
+		{"Secret", gist(false, "", "main.go"), "Secret gist main.go, updated 3 hours ago"},
+		{"Many files", gist(true, "", "a.go", "b.go", "c.go", "d.go"), "a.go, b.go and 2 other files, updated 3 hours ago"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := describeGist(tt.gist, now, time.UTC); got != tt.want {
+				t.Errorf("describeGist() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const graphQLURL = "https://api.github.com/graphql"
+
+// A minimal GraphQL client for what the REST API and go-github don't offer. It shares the
+// REST client's auth transport, so it works the same on App Engine and the command line.
+type graphQLClient struct {
+	client *http.Client
+	url    string
+}
+
+type graphQLRequest struct {
+	Query     string                 `json:"query"`
+	Variables map[string]interface{} `json:"variables,omitempty"`
+}
+
+type graphQLResponse struct {
+	Data   json.RawMessage `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+// GraphQL has no anonymous access, so callers need to check there's a token first.
+func createGraphQLClient(ctx context.Context, accessToken string) *graphQLClient {
+	return &graphQLClient{authHTTPClient(ctx, accessToken), graphQLURL}
+}
+
+// Run a query and decode its data into v.
+func (c *graphQLClient) query(ctx context.Context, query string, variables map[string]interface{}, v interface{}) error {
+	body, err := json.Marshal(&graphQLRequest{query, variables})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest("POST", c.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("graphql: %s", resp.Status)
+	}
+
+	var decoded graphQLResponse
+	if err := json.NewDecoder(resp.Body).Decode(&decoded); err != nil {
+		return err
+	}
+	if len(decoded.Errors) > 0 {
+		var messages []string
+		for _, e := range decoded.Errors {
+			messages = append(messages, e.Message)
+		}
+		return errors.New("graphql: " + strings.Join(messages, "; "))
+	}
+	return json.Unmarshal(decoded.Data, v)
+}
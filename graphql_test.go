@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func Test_graphQLClient_query(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		want    string
+		wantErr bool
+	}{
+		{"Data", http.StatusOK, `{"data": {"viewer": {"login": "ada"}}}`, "ada", false},
+		{"Errors", http.StatusOK, `{"data": null, "errors": [{"message": "Field 'nope' doesn't exist"}]}`, "", true},
+		{"Unauthorized", http.StatusUnauthorized, `{"message": "Bad credentials"}`, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var req graphQLRequest
+				if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Query == "" || req.Variables["n"] != 1.0 {
+					t.Errorf("bad request %+v, %v", req, err)
+				}
+				w.WriteHeader(tt.status)
+				fmt.Fprint(w, tt.body)
+			}))
+			defer server.Close()
+
+			var got struct {
+				Viewer struct {
+					Login string `json:"login"`
+				} `json:"viewer"`
+			}
+			client := &graphQLClient{server.Client(), server.URL}
+			err := client.query(context.Background(), "query { viewer { login } }", map[string]interface{}{"n": 1}, &got)
+			if (err != nil) != tt.wantErr || got.Viewer.Login != tt.want {
+				t.Errorf("graphQLClient.query() = %v, %v, want %v, wantErr %v", got.Viewer.Login, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/google/go-github/github"
+)
+
+const (
+	CommentIntent     = "comment_intent"
+	CloseIssueIntent  = "close_issue_intent"
+	ReopenIssueIntent = "reopen_issue_intent"
+
+	pendingCloseIssue = "close_issue"
+)
+
+// Find the issue the user means: a number from the list, or "it" for the last one they heard
+// about or acted on.
+func resolveIssueTarget(session *SessionState, number string) (string, error) {
+	if number == "" {
+		if session.Focus == "" {
+			return "", errors.New("Which one? Say a number from the list.")
+		}
+		return session.Focus, nil
+	}
+
+	item, err := resolveOrdinal(session, number)
+	if err != nil {
+		return "", err
+	}
+	if _, _, _, ok := parseIssueRef(item.Ref); !ok {
+		return "", fmt.Errorf("Number %s isn't an issue or pull request.", number)
+	}
+	return item.Ref, nil
+}
+
+// Say an issue reference, like "number 12 in dailygithub". Repo references are said as they are.
+func spokenIssueRef(ref string) string {
+	_, repo, number, ok := parseIssueRef(ref)
+	if !ok {
+		return ref // Just a repo
+	}
+	return fmt.Sprintf("number %d in %s", number, repo)
+}
+
+// Turn permission problems into something to say, and pass anything else up.
+func issueActionError(err error, action, ref string) (FulfillmentBuilder, error) {
+	var reply string
+	switch {
+	case hasStatus(err, http.StatusForbidden), hasStatus(err, http.StatusNotFound):
+		reply = fmt.Sprintf("Sorry, you don't have permission to %s %s.", action, spokenIssueRef(ref))
+	case hasStatus(err, http.StatusGone):
+		reply = fmt.Sprintf("Sorry, issues are turned off in %s.", strings.SplitN(ref, "#", 2)[0])
+	default:
+		return nil, err
+	}
+	return &SpokenResponse{reply, reply, nil}, nil
+}
+
+// Carry the session on, remembering ref for "it".
+func focusedState(session *SessionState, ref string) *SessionState {
+	state := *session
+	state.PendingAction = ""
+	state.Focus = ref
+	return &state
+}
+
+func commentOnIssue(ctx context.Context, accessToken string, session *SessionState, number, body string) (FulfillmentBuilder, error) {
+	ref, err := resolveIssueTarget(session, number)
+	if err != nil {
+		return &SpokenResponse{err.Error(), err.Error(), session}, nil
+	}
+	if body = strings.TrimSpace(body); body == "" {
+		reply := "What should the comment say?"
+		return &SpokenResponse{reply, reply, session}, nil
+	}
+
+	owner, repo, n, _ := parseIssueRef(ref)
+	client := createGithubClient(ctx, accessToken)
+	comment, _, err := client.Issues.CreateComment(ctx, owner, repo, n, &github.IssueComment{Body: &body})
+	if err != nil {
+		return issueActionError(err, "comment on", ref)
+	}
+
+	done := fmt.Sprintf("Commented on %s.", spokenIssueRef(ref))
+	return &SpokenResponse{done + "\n" + comment.GetHTMLURL(), done, focusedState(session, ref)}, nil
+}
+
+// Closing is confirmed first, so just ask the question here.
+func confirmCloseIssue(session *SessionState, number string) FulfillmentBuilder {
+	ref, err := resolveIssueTarget(session, number)
+	if err != nil {
+		return &SpokenResponse{err.Error(), err.Error(), session}
+	}
+
+	state := focusedState(session, ref)
+	state.PendingAction = pendingCloseIssue
+	question := fmt.Sprintf("Close %s?", spokenIssueRef(ref))
+	return &SpokenResponse{question, question, state}
+}
+
+// Close the focused issue, after the user said yes.
+func closeIssue(ctx context.Context, accessToken string, session *SessionState) (FulfillmentBuilder, error) {
+	return setIssueState(ctx, accessToken, session, session.Focus, "closed")
+}
+
+func reopenIssue(ctx context.Context, accessToken string, session *SessionState, number string) (FulfillmentBuilder, error) {
+	ref, err := resolveIssueTarget(session, number)
+	if err != nil {
+		return &SpokenResponse{err.Error(), err.Error(), session}, nil
+	}
+	return setIssueState(ctx, accessToken, session, ref, "open")
+}
+
+func setIssueState(ctx context.Context, accessToken string, session *SessionState, ref, state string) (FulfillmentBuilder, error) {
+	owner, repo, n, ok := parseIssueRef(ref)
+	if !ok {
+		return nil, fmt.Errorf("bad issue reference %q", ref)
+	}
+
+	action, done := "close", "Closed"
+	if state == "open" {
+		action, done = "reopen", "Reopened"
+	}
+
+	client := createGithubClient(ctx, accessToken)
+	if _, _, err := client.Issues.Edit(ctx, owner, repo, n, &github.IssueRequest{State: &state}); err != nil {
+		return issueActionError(err, action, ref)
+	}
+
+	reply := fmt.Sprintf("%s %s.", done, spokenIssueRef(ref))
+	return &SpokenResponse{reply, reply, focusedState(session, ref)}, nil
+}
@@ -0,0 +1,32 @@
+package main
+
+import "testing"
+
+func Test_resolveIssueTarget(t *testing.T) {
+	list := &PagedList{Kind: AssignedIssuesIntent, PageSize: 5, Items: []ListItem{
+		{Ref: "ephraimkunz/DailyGithub#12"},
+		{Ref: "ephraimkunz/DailyGithub"},
+	}}
+	tests := []struct {
+		name    string
+		session *SessionState
+		number  string
+		want    string
+		wantErr bool
+	}{
+		{"Number", &SessionState{List: list}, "1", "ephraimkunz/DailyGithub#12", false},
+		{"It", &SessionState{List: list, Focus: "golang/go#3"}, "", "golang/go#3", false},
+		{"Number over it", &SessionState{List: list, Focus: "golang/go#3"}, "1", "ephraimkunz/DailyGithub#12", false},
+		{"Not an issue", &SessionState{List: list}, "2", "", true},
+		{"Out of range", &SessionState{List: list}, "3", "", true},
+		{"Nothing to pick", &SessionState{}, "", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolveIssueTarget(tt.session, tt.number)
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("resolveIssueTarget() = %v, %v, want %v, wantErr %v", got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
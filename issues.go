@@ -0,0 +1,201 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/go-github/github"
+)
+
+const (
+	CreateIssueIntent = "create_issue_intent"
+
+	pendingCreateIssue = "create_issue"
+
+	assignedIssuesPerPage = 100 // Some filtering happens here, so fetch more than gets read out
+	maxAssignedIssues     = 20  // Read at most this many, a page at a time
+)
+
+// A spoken sort order and what it means to the API
+type issueSort struct {
+	Sort      string
+	Direction string
+	Spoken    string // Said after the list header, like "oldest first"
+}
+
+var issueSorts = map[string]issueSort{
+	"oldest":           {"created", "asc", "oldest first"},
+	"oldest first":     {"created", "asc", "oldest first"},
+	"newest":           {"created", "desc", "newest first"},
+	"newest first":     {"created", "desc", "newest first"},
+	"recently updated": {"updated", "desc", "most recently updated first"},
+	"updated":          {"updated", "desc", "most recently updated first"},
+	"most commented":   {"comments", "desc", "most commented first"},
+	"comments":         {"comments", "desc", "most commented first"},
+}
+
+// Narrows and orders the assigned issues read out. Empty fields match everything.
+type IssueFilter struct {
+	Repo      string // Repository name as spoken, matched loosely
+	Label     string
+	Milestone string // Milestone title, matched loosely
+	Type      string // "PullRequest" or "Issue"
+	Sort      *issueSort
+}
+
+// The filter given by the request's slots.
+func issueFilter(req *IntentRequest) (IssueFilter, error) {
+	filter := IssueFilter{Repo: req.Repo, Label: req.Label, Milestone: req.Milestone}
+	if req.Type != "" {
+		types, ok := resolveSpokenList(req.Type, notificationTypes)
+		if !ok || len(types) != 1 || (types[0] != "PullRequest" && types[0] != "Issue") {
+			return filter, fmt.Errorf("I can show you just issues or just pull requests, but not %s.", req.Type)
+		}
+		filter.Type = types[0]
+	}
+	if req.Sort != "" {
+		sort, ok := issueSorts[strings.ToLower(strings.TrimSpace(req.Sort))]
+		if !ok {
+			return filter, fmt.Errorf("I can sort by oldest, newest, recently updated or most commented, but not %s.", req.Sort)
+		}
+		filter.Sort = &sort
+	}
+	return filter, nil
+}
+
+// Labels and sorting are done by the API, everything else by apply.
+func (filter IssueFilter) listOptions() *github.IssueListOptions {
+	opt := &github.IssueListOptions{ListOptions: github.ListOptions{PerPage: assignedIssuesPerPage}}
+	if filter.Label != "" {
+		opt.Labels = []string{filter.Label}
+	}
+	if filter.Sort != nil {
+		opt.Sort, opt.Direction = filter.Sort.Sort, filter.Sort.Direction
+	}
+	return opt
+}
+
+func (filter IssueFilter) matches(issue *github.Issue) bool {
+	if filter.Type == "PullRequest" && !issue.IsPullRequest() || filter.Type == "Issue" && issue.IsPullRequest() {
+		return false
+	}
+	if filter.Repo != "" {
+		want := normalizeName(filter.Repo)
+		if normalizeName(issue.Repository.GetName()) != want && normalizeName(issue.Repository.GetFullName()) != want {
+			return false
+		}
+	}
+	if filter.Milestone != "" && normalizeName(issue.Milestone.GetTitle()) != normalizeName(filter.Milestone) {
+		return false
+	}
+	return true
+}
+
+func (filter IssueFilter) apply(issues []*github.Issue) []*github.Issue {
+	var matched []*github.Issue
+	for _, issue := range issues {
+		if filter.matches(issue) && len(matched) < maxAssignedIssues {
+			matched = append(matched, issue)
+		}
+	}
+	return matched
+}
+
+// Say what the filter lets through, like "pull requests assigned to you in dailygithub labelled bug".
+func (filter IssueFilter) describe() string {
+	noun := "issues"
+	if filter.Type == "PullRequest" {
+		noun = "pull requests"
+	}
+	noun += " assigned to you"
+	if filter.Repo != "" {
+		noun += " in " + filter.Repo
+	}
+	if filter.Label != "" {
+		noun += " labelled " + filter.Label
+	}
+	if filter.Milestone != "" {
+		noun += " for the " + filter.Milestone + " milestone"
+	}
+	return noun
+}
+
+// An issue being put together over one or more turns, then waiting on the user to confirm it
+type IssueDraft struct {
+	Repo  string `json:"repo"` // Full name, like "owner/repo", once resolved
+	Title string `json:"title"`
+}
+
+// Fill in the draft from earlier turns with what was just said. Newly spoken parts win.
+func fillDraft(previous *IssueDraft, spokenRepo, title string) IssueDraft {
+	var draft IssueDraft
+	if previous != nil {
+		draft = *previous
+	}
+	if title = strings.TrimSpace(title); title != "" {
+		draft.Title = title
+	}
+	if spokenRepo = strings.TrimSpace(spokenRepo); spokenRepo != "" {
+		draft.Repo = spokenRepo
+	}
+	return draft
+}
+
+// Ask for whatever the draft is missing, or to confirm it once it's complete. The draft is kept
+// in the session either way, so the answer fills in the rest.
+func askAboutDraft(session *SessionState, draft IssueDraft) *SpokenResponse {
+	state := *session
+	state.Draft = &draft
+	state.PendingAction = ""
+
+	var question string
+	switch {
+	case draft.Repo == "":
+		question = "Which repo should the issue go in?"
+	case draft.Title == "":
+		question = fmt.Sprintf("What should the issue in %s be titled?", draft.Repo)
+	default:
+		state.PendingAction = pendingCreateIssue
+		question = fmt.Sprintf("Create an issue in %s titled %s?", draft.Repo, draft.Title)
+	}
+	return &SpokenResponse{question, question, &state}
+}
+
+// Build up the issue from what's been said so far, then ask before creating anything. Alexa
+// collects the slots itself, but the Assistant can arrive here with either part missing.
+func draftIssue(ctx context.Context, accessToken string, req *IntentRequest) (FulfillmentBuilder, error) {
+	draft := fillDraft(req.Session.Draft, req.Repo, req.Title)
+	if strings.TrimSpace(req.Repo) != "" {
+		repo, err := resolveRepo(ctx, createGithubClient(ctx, accessToken), req.Repo)
+		if err != nil {
+			return nil, err
+		} else if repo == "" {
+			reply := fmt.Sprintf("I couldn't find a repo called %s.", req.Repo)
+			return &SpokenResponse{reply, reply, req.Session}, nil
+		}
+		draft.Repo = repo
+	}
+	return askAboutDraft(req.Session, draft), nil
+}
+
+func createIssue(ctx context.Context, accessToken string, session *SessionState) (FulfillmentBuilder, error) {
+	draft := session.Draft
+	if draft == nil {
+		return nil, errors.New("no issue to create")
+	}
+	owner, name, ok := parseRepoRef(draft.Repo)
+	if !ok {
+		return nil, fmt.Errorf("bad repository reference %q", draft.Repo)
+	}
+
+	client := createGithubClient(ctx, accessToken)
+	issue, _, err := client.Issues.Create(ctx, owner, name, &github.IssueRequest{Title: &draft.Title})
+	if err != nil {
+		return issueActionError(err, "create issues in", draft.Repo)
+	}
+
+	done := fmt.Sprintf("Created issue number %d in %s.", issue.GetNumber(), draft.Repo)
+	return &SpokenResponse{done + "\n" + issue.GetHTMLURL(), done, nil}, nil
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/google/go-github/github"
+)
+
+func Test_issueFilter(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     IntentRequest
+		want    string
+		wantErr bool
+	}{
+		{"Empty", IntentRequest{}, "issues assigned to you", false},
+		{"Pull requests", IntentRequest{Type: "pull requests"}, "pull requests assigned to you", false},
+		{"Everything", IntentRequest{Repo: "dailygithub", Label: "bug", Milestone: "1.0", Type: "issues"}, "issues assigned to you in dailygithub labelled bug for the 1.0 milestone", false},
+		{"Bad type", IntentRequest{Type: "releases"}, "", true},
+		{"Bad sort", IntentRequest{Sort: "alphabetical"}, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := issueFilter(&tt.req)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("issueFilter() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && got.describe() != tt.want {
+				t.Errorf("issueFilter().describe() = %v, want %v", got.describe(), tt.want)
+			}
+		})
+	}
+}
+
+func Test_IssueFilter_matches(t *testing.T) {
+	issue := &github.Issue{
+		Repository: &github.Repository{Name: github.String("DailyGithub"), FullName: github.String("ephraimkunz/DailyGithub")},
+		Milestone:  &github.Milestone{Title: github.String("v1.0")},
+	}
+	pull := &github.Issue{
+		Repository:       issue.Repository,
+		PullRequestLinks: &github.PullRequestLinks{URL: github.String("https://api.github.com/pulls/1")},
+	}
+	tests := []struct {
+		name   string
+		filter IssueFilter
+		issue  *github.Issue
+		want   bool
+	}{
+		{"Empty", IssueFilter{}, issue, true},
+		{"Issues only", IssueFilter{Type: "Issue"}, issue, true},
+		{"Issues only skips pulls", IssueFilter{Type: "Issue"}, pull, false},
+		{"Pulls only", IssueFilter{Type: "PullRequest"}, pull, true},
+		{"Pulls only skips issues", IssueFilter{Type: "PullRequest"}, issue, false},
+		{"Repo", IssueFilter{Repo: "daily github"}, issue, true},
+		{"Wrong repo", IssueFilter{Repo: "dotfiles"}, issue, false},
+		{"Milestone", IssueFilter{Milestone: "V1.0"}, issue, true},
+		{"No milestone", IssueFilter{Milestone: "v1.0"}, pull, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.matches(tt.issue); got != tt.want {
+				t.Errorf("IssueFilter.matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_draftAcrossTurns(t *testing.T) {
+	// Each turn fills in what was said with the repo already resolved, then asks about the rest
+	type turn struct {
+		repo, title  string
+		wantQuestion string
+		wantPending  string
+	}
+	tests := []struct {
+		name  string
+		turns []turn
+		want  IssueDraft
+	}{
+		{"All at once", []turn{
+			{"ephraimkunz/DailyGithub", "Login fails", "Create an issue in ephraimkunz/DailyGithub titled Login fails?", pendingCreateIssue},
+		}, IssueDraft{"ephraimkunz/DailyGithub", "Login fails"}},
+		{"Title later", []turn{
+			{"ephraimkunz/DailyGithub", "", "What should the issue in ephraimkunz/DailyGithub be titled?", ""},
+			{"", "Login fails", "Create an issue in ephraimkunz/DailyGithub titled Login fails?", pendingCreateIssue},
+		}, IssueDraft{"ephraimkunz/DailyGithub", "Login fails"}},
+		{"Repo later", []turn{
+			{"", "Login fails", "Which repo should the issue go in?", ""},
+			{"ephraimkunz/DailyGithub", "", "Create an issue in ephraimkunz/DailyGithub titled Login fails?", pendingCreateIssue},
+		}, IssueDraft{"ephraimkunz/DailyGithub", "Login fails"}},
+		{"Changed title", []turn{
+			{"golang/go", "Crash", "Create an issue in golang/go titled Crash?", pendingCreateIssue},
+			{"", "Crash on start", "Create an issue in golang/go titled Crash on start?", pendingCreateIssue},
+		}, IssueDraft{"golang/go", "Crash on start"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			session := &SessionState{}
+			for i, turn := range tt.turns {
+				resp := askAboutDraft(session, fillDraft(session.Draft, turn.repo, turn.title))
+				if resp.text != turn.wantQuestion || resp.state.PendingAction != turn.wantPending {
+					t.Errorf("turn %d = %q pending %q, want %q pending %q", i+1, resp.text, resp.state.PendingAction, turn.wantQuestion, turn.wantPending)
+				}
+				session = resp.state
+			}
+			if *session.Draft != tt.want {
+				t.Errorf("draft = %+v, want %+v", *session.Draft, tt.want)
+			}
+		})
+	}
+}
+
+func Test_createIssueErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		want   string
+	}{
+		{"No push rights", http.StatusForbidden, "Sorry, you don't have permission to create issues in golang/go."},
+		{"Private", http.StatusNotFound, "Sorry, you don't have permission to create issues in golang/go."},
+		{"Issues off", http.StatusGone, "Sorry, issues are turned off in golang/go."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := &github.ErrorResponse{Response: &http.Response{StatusCode: tt.status}}
+			builder, err2 := issueActionError(err, "create issues in", "golang/go")
+			if err2 != nil {
+				t.Fatalf("issueActionError() error = %v", err2)
+			}
+			if got := builder.(*SpokenResponse).text; got != tt.want {
+				t.Errorf("issueActionError() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/github"
+)
+
+const (
+	LookupUserIntent = "lookup_user_intent"
+	LookupRepoIntent = "lookup_repo_intent"
+
+	// OAuth app credentials, used for public lookups when the user hasn't linked an account
+	githubClientIDEnv     = "GITHUB_CLIENT_ID"
+	githubClientSecretEnv = "GITHUB_CLIENT_SECRET"
+	maxTopRepos           = 3
+)
+
+// Any public user or org, with their most starred repos
+type UserProfile struct {
+	user     *github.User
+	topRepos []*github.Repository
+}
+
+// Any public repo
+type RepoProfile struct {
+	repo *github.Repository
+}
+
+// A client for public data. Uses the user's token if they linked an account, and otherwise
+// the app's credentials if set, which get a higher rate limit than anonymous calls. Those go
+// in a basic auth header, since Github no longer takes them as query parameters.
+func publicGithubClient(ctx context.Context, accessToken string) *github.Client {
+	id, secret := os.Getenv(githubClientIDEnv), os.Getenv(githubClientSecretEnv)
+	if accessToken != "" || id == "" || secret == "" {
+		return createGithubClient(ctx, accessToken)
+	}
+
+	transport := &github.BasicAuthTransport{Username: id, Password: secret, Transport: httpTransport(ctx)}
+	return github.NewClient(transport.Client())
+}
+
+// Logins can't have spaces, so "tor valds" is "torvalds".
+func spokenLogin(spoken string) string {
+	return strings.Replace(strings.TrimSpace(spoken), " ", "", -1)
+}
+
+func lookupUser(ctx context.Context, accessToken string, spoken string) (FulfillmentBuilder, error) {
+	login := spokenLogin(spoken)
+	if login == "" {
+		reply := "Who should I look up?"
+		return &SpokenResponse{reply, reply, nil}, nil
+	}
+
+	client := publicGithubClient(ctx, accessToken)
+	user, _, err := client.Users.Get(ctx, login)
+	if hasStatus(err, http.StatusNotFound) {
+		reply := fmt.Sprintf("I couldn't find anyone called %s on Github.", spoken)
+		return &SpokenResponse{reply, reply, nil}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	// Listing repos comes back alphabetically, so search to get the most starred of any number
+	profile := &UserProfile{user: user}
+	query := fmt.Sprintf("user:%s fork:false", user.GetLogin())
+	opt := &github.SearchOptions{Sort: "stars", Order: "desc", ListOptions: github.ListOptions{PerPage: maxTopRepos}}
+	if result, _, err := client.Search.Repositories(ctx, query, opt); err != nil {
+		debugf(ctx, "Failed to find top repos for %s: %v", user.GetLogin(), err)
+	} else {
+		for i := range result.Repositories {
+			profile.topRepos = append(profile.topRepos, &result.Repositories[i])
+		}
+	}
+	return profile, nil
+}
+
+func (profile *UserProfile) describe() string {
+	user := profile.user
+	who := user.GetLogin()
+	if user.GetName() != "" && user.GetName() != user.GetLogin() {
+		who = fmt.Sprintf("%s, %s on Github,", user.GetName(), user.GetLogin())
+	}
+
+	var parts []string
+	if user.GetType() == "Organization" {
+		parts = append(parts, fmt.Sprintf("%s is an organization with %s.", who, pluralize(user.GetPublicRepos(), "public repo", "public repos")))
+	} else {
+		parts = append(parts, fmt.Sprintf("%s has %s and %s.", who,
+			pluralize(user.GetFollowers(), "follower", "followers"), pluralize(user.GetPublicRepos(), "public repo", "public repos")))
+	}
+	if bio := strings.TrimSpace(user.GetBio()); bio != "" {
+		parts = append(parts, "Their bio says: "+strings.TrimRight(bio, ".")+".")
+	}
+
+	var repos []string
+	for _, repo := range profile.topRepos {
+		repos = append(repos, fmt.Sprintf("%s with %s", repo.GetName(), pluralize(repo.GetStargazersCount(), "star", "stars")))
+	}
+	switch len(repos) {
+	case 0:
+	case 1:
+		parts = append(parts, fmt.Sprintf("Their top repo is %s.", repos[0]))
+	default:
+		parts = append(parts, fmt.Sprintf("Their top repos are %s.", joinWords(repos)))
+	}
+	return strings.Join(parts, " ")
+}
+
+func (profile *UserProfile) buildFulfillment(ctx context.Context) *FulfillmentResp {
+	description := profile.describe()
+	debugf(ctx, "Built fulfillment with string: %s", description)
+	return &FulfillmentResp{"<speak>" + description + "</speak>", description + "\n" + profile.user.GetHTMLURL()}
+}
+
+// Turn a spoken "golang slash go" into "golang/go". Returns false if no owner was said.
+func spokenRepoRef(spoken string) (string, bool) {
+	ref := strings.Replace(strings.Replace(strings.TrimSpace(spoken), " slash ", "/", 1), " ", "", -1)
+	_, _, ok := parseRepoRef(ref)
+	return ref, ok
+}
+
+// Look up a repo spoken as "owner/repo". Just a name works too if the user linked an account
+// and it's one of theirs.
+func lookupRepo(ctx context.Context, accessToken string, spoken string) (FulfillmentBuilder, error) {
+	spoken = strings.TrimSpace(spoken)
+	if spoken == "" {
+		reply := "Which repo should I look up?"
+		return &SpokenResponse{reply, reply, nil}, nil
+	}
+
+	client := publicGithubClient(ctx, accessToken)
+	ref, ok := spokenRepoRef(spoken)
+	if !ok {
+		if accessToken == "" {
+			reply := fmt.Sprintf("Who owns %s? Say it like golang slash go.", spoken)
+			return &SpokenResponse{reply, reply, nil}, nil
+		}
+
+		fullName, err := resolveRepo(ctx, client, spoken)
+		if err != nil {
+			return nil, err
+		} else if fullName == "" {
+			reply := fmt.Sprintf("I couldn't find a repo called %s.", spoken)
+			return &SpokenResponse{reply, reply, nil}, nil
+		}
+		ref = fullName
+	}
+
+	owner, name, _ := parseRepoRef(ref)
+	repo, _, err := client.Repositories.Get(ctx, owner, name)
+	if hasStatus(err, http.StatusNotFound) {
+		reply := fmt.Sprintf("I couldn't find a repo called %s.", spoken)
+		return &SpokenResponse{reply, reply, nil}, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return &RepoProfile{repo}, nil
+}
+
+func (profile *RepoProfile) describe(now time.Time, loc *time.Location) string {
+	repo := profile.repo
+	parts := []string{repo.GetFullName() + "."}
+	if description := strings.TrimSpace(repo.GetDescription()); description != "" {
+		parts[0] = fmt.Sprintf("%s: %s.", repo.GetFullName(), strings.TrimRight(description, "."))
+	}
+
+	parts = append(parts, fmt.Sprintf("It has %s and %s.",
+		pluralize(repo.GetStargazersCount(), "star", "stars"), pluralize(repo.GetOpenIssuesCount(), "open issue", "open issues")))
+	if repo.GetLanguage() != "" {
+		parts = append(parts, fmt.Sprintf("It's written in %s.", repo.GetLanguage()))
+	}
+	if !repo.GetPushedAt().IsZero() {
+		parts = append(parts, fmt.Sprintf("It was last pushed %s.", relativeTime(repo.GetPushedAt().Time, now, loc)))
+	}
+	if repo.GetArchived() {
+		parts = append(parts, "It's archived.")
+	}
+	return strings.Join(parts, " ")
+}
+
+func (profile *RepoProfile) buildFulfillment(ctx context.Context) *FulfillmentResp {
+	description := profile.describe(time.Now(), preferencesFromContext(ctx).location())
+	debugf(ctx, "Built fulfillment with string: %s", description)
+	return &FulfillmentResp{"<speak>" + description + "</speak>", description + "\n" + profile.repo.GetHTMLURL()}
+}
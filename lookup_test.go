@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/github"
+)
+
+func Test_UserProfile_describe(t *testing.T) {
+	repo := func(name string, stars int) *github.Repository {
+		return &github.Repository{Name: github.String(name), StargazersCount: github.Int(stars)}
+	}
+	tests := []struct {
+		name    string
+		profile UserProfile
+		want    string
+	}{
+		{"User", UserProfile{
+			user:     &github.User{Login: github.String("torvalds"), Name: github.String("Linus Torvalds"), Type: github.String("User"), Followers: github.Int(1000), PublicRepos: github.Int(7), Bio: github.String("Just a kernel hacker.")},
+			topRepos: []*github.Repository{repo("linux", 150000), repo("subsurface", 1)},
+		}, "Linus Torvalds, torvalds on Github, has 1000 followers and 7 public repos. Their bio says: Just a kernel hacker. Their top repos are linux with 150000 stars and subsurface with 1 star."},
+		{"Org", UserProfile{
+			user:     &github.User{Login: github.String("golang"), Type: github.String("Organization"), PublicRepos: github.Int(50)},
+			topRepos: []*github.Repository{repo("go", 120000)},
+		}, "golang is an organization with 50 public repos. Their top repo is go with 120000 stars."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.profile.describe(); got != tt.want {
+				t.Errorf("UserProfile.describe() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_RepoProfile_describe(t *testing.T) {
+	now := time.Date(2018, 3, 10, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		repo *github.Repository
+		want string
+	}{
+		{"Full", &github.Repository{
+			FullName: github.String("golang/go"), Description: github.String("The Go programming language"), StargazersCount: github.Int(120000),
+			OpenIssuesCount: github.Int(1), Language: github.String("Go"), PushedAt: &github.Timestamp{Time: now.Add(-2 * time.Hour)},
+		}, "golang/go: The Go programming language. It has 120000 stars and 1 open issue. It's written in Go. It was last pushed 2 hours ago."},
+		{"Bare", &github.Repository{FullName: github.String("ada/notes"), Archived: github.Bool(true)},
+			"ada/notes. It has 0 stars and 0 open issues. It's archived."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := (&RepoProfile{tt.repo}).describe(now, time.UTC); got != tt.want {
+				t.Errorf("RepoProfile.describe() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_spokenRepoRef(t *testing.T) {
+	tests := []struct {
+		name   string
+		spoken string
+		want   string
+		wantOK bool
+	}{
+		{"Slash", "golang/go", "golang/go", true},
+		{"Spoken slash", "golang slash go", "golang/go", true},
+		{"Spaced name", "ephraim kunz slash daily github", "ephraimkunz/dailygithub", true},
+		{"Bare name", "go", "go", false},
+		{"No owner", "/go", "/go", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, ok := spokenRepoRef(tt.spoken); got != tt.want || ok != tt.wantOK {
+				t.Errorf("spokenRepoRef() = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func Test_lookupRepoWithoutOwner(t *testing.T) {
+	ctx := withCLI(context.Background(), &cliOptions{ioutil.Discard, nil})
+	builder, err := lookupRepo(ctx, "", "daily github")
+	if err != nil {
+		t.Fatalf("lookupRepo() error = %v", err)
+	}
+	if want := "Who owns daily github? Say it like golang slash go."; builder.(*SpokenResponse).text != want {
+		t.Errorf("lookupRepo() = %v, want %v", builder.(*SpokenResponse).text, want)
+	}
+}
@@ -1,7 +1,14 @@
 package main
 
-import "google.golang.org/appengine"
+import (
+	"os"
+
+	"google.golang.org/appengine"
+)
 
 func main() {
+	if len(os.Args) > 1 {
+		os.Exit(runCLI(os.Args[1:], os.Stdout, os.Stderr))
+	}
 	appengine.Main()
 }
@@ -0,0 +1,394 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/google/go-github/github"
+)
+
+const (
+	MarkAllReadIntent = "mark_all_read_intent"
+	MarkReadIntent    = "mark_read_intent"
+
+	pendingMarkAllRead = "mark_all_read"
+
+	notificationsPerPage = 100 // Filtering happens here, so fetch more than gets read out
+)
+
+// Bulk actions are confirmed first, so just ask the question here. It names what
+// markAllNotificationsRead will touch: the threads listed when the list left some out,
+// and otherwise every unread notification.
+func confirmMarkAllRead(session *SessionState) FulfillmentBuilder {
+	state := *session
+	state.PendingAction = pendingMarkAllRead
+	question := markAllReadQuestion(session)
+	return &SpokenResponse{question, question, &state}
+}
+
+func markAllReadQuestion(session *SessionState) string {
+	count := len(sessionRefs(session, NotificationsIntent))
+	switch {
+	case count == 0:
+		return "Mark all of your unread notifications as read?"
+	case session.NotificationsPartial && count == 1:
+		return "Mark just that notification as read? The others stay unread."
+	case session.NotificationsPartial:
+		return fmt.Sprintf("Mark just those %d notifications as read? The others stay unread.", count)
+	case count == 1:
+		return "Mark your only unread notification as read?"
+	default:
+		return fmt.Sprintf("Mark all %d of your unread notifications as read?", count)
+	}
+}
+
+func markAllNotificationsRead(ctx context.Context, accessToken string, session *SessionState) (FulfillmentBuilder, error) {
+	lastRead := session.NotificationsReadAt
+	if lastRead.IsZero() {
+		lastRead = time.Now() // Nothing was read out this session, so clear everything
+	}
+
+	client := createGithubClient(ctx, accessToken)
+	if ids := sessionRefs(session, NotificationsIntent); session.NotificationsPartial && len(ids) > 0 {
+		// Only what was read out, not the threads the filter, priority cap or paging left out
+		for _, id := range ids {
+			if _, err := client.Activity.MarkThreadRead(ctx, id); err != nil {
+				return nil, err
+			}
+		}
+		done := "Done. Those notifications are marked as read."
+		return &SpokenResponse{done, done, nil}, nil
+	}
+
+	if _, err := client.Activity.MarkNotificationsRead(ctx, lastRead); err != nil {
+		return nil, err
+	}
+
+	done := "Done. Your notifications are marked as read."
+	return &SpokenResponse{done, done, nil}, nil
+}
+
+// Mark a single notification, numbered as it was read out, as read.
+func markNotificationRead(ctx context.Context, accessToken string, session *SessionState, number string) (FulfillmentBuilder, error) {
+	ids := sessionRefs(session, NotificationsIntent)
+	i, err := strconv.Atoi(number)
+	if err != nil || i < 1 || i > len(ids) {
+		var reply string
+		if len(ids) == 0 {
+			reply = "Ask for your notifications first, then tell me which number to mark as read."
+		} else {
+			reply = fmt.Sprintf("Please pick a number between 1 and %d.", len(ids))
+		}
+		return &SpokenResponse{reply, reply, session}, nil
+	}
+
+	client := createGithubClient(ctx, accessToken)
+	if _, err := client.Activity.MarkThreadRead(ctx, ids[i-1]); err != nil {
+		return nil, err
+	}
+
+	state := *session
+	state.PendingAction = ""
+	done := fmt.Sprintf("Marked number %d as read.", i)
+	return &SpokenResponse{done, done, &state}, nil
+}
+
+// Spoken notification reasons and the API values they stand for
+var notificationReasons = map[string]string{
+	"mention":          "mention",
+	"mentions":         "mention",
+	"team mention":     "team_mention",
+	"team mentions":    "team_mention",
+	"review request":   "review_requested",
+	"review requests":  "review_requested",
+	"review requested": "review_requested",
+	"reviews":          "review_requested",
+	"assign":           "assign",
+	"assigned":         "assign",
+	"assignment":       "assign",
+	"assignments":      "assign",
+	"ci":               "ci_activity",
+	"ci activity":      "ci_activity",
+	"build":            "ci_activity",
+	"builds":           "ci_activity",
+	"comment":          "comment",
+	"comments":         "comment",
+}
+
+// Spoken subject types and the API values they stand for
+var notificationTypes = map[string]string{
+	"pull request":  "PullRequest",
+	"pull requests": "PullRequest",
+	"pr":            "PullRequest",
+	"prs":           "PullRequest",
+	"issue":         "Issue",
+	"issues":        "Issue",
+	"release":       "Release",
+	"releases":      "Release",
+	"commit":        "Commit",
+	"commits":       "Commit",
+}
+
+// How to say the API values back, in the plural
+var (
+	reasonNames = map[string]string{
+		"mention":          "mentions",
+		"team_mention":     "team mentions",
+		"review_requested": "review requests",
+		"assign":           "assignments",
+		"ci_activity":      "CI activity",
+		"comment":          "comments",
+	}
+	typeNames = map[string]string{
+		"PullRequest": "pull requests",
+		"Issue":       "issues",
+		"Release":     "releases",
+		"Commit":      "commits",
+	}
+)
+
+// Reasons the user is always participating in, so the API can filter for them.
+var participatingReasons = map[string]bool{
+	"mention":          true,
+	"team_mention":     true,
+	"review_requested": true,
+	"assign":           true,
+	"comment":          true,
+}
+
+// Narrows the notifications read out. Empty fields match everything.
+type NotificationFilter struct {
+	Reasons []string // API reasons, like "review_requested"
+	Types   []string // Subject types, like "PullRequest"
+	Repo    string   // Repository name as spoken, matched loosely
+}
+
+// Turn something like "mentions and review requests" into API values. Values already in API
+// form are accepted too, since that's what the command line and config file use.
+func resolveSpokenList(spoken string, known map[string]string) ([]string, bool) {
+	spoken = strings.Replace(strings.ToLower(spoken), " and ", ",", -1)
+	var values []string
+	for _, word := range strings.Split(spoken, ",") {
+		word = strings.TrimSpace(word)
+		if word == "" {
+			continue
+		}
+
+		value, ok := known[word]
+		if !ok {
+			for _, v := range known {
+				if strings.ToLower(v) == word {
+					value, ok = v, true
+					break
+				}
+			}
+		}
+		if !ok {
+			return nil, false
+		}
+		if !containsString(values, value) {
+			values = append(values, value)
+		}
+	}
+	return values, len(values) > 0
+}
+
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// The filter given by the request's slots alone.
+func resolveNotificationFilter(req *IntentRequest) (NotificationFilter, error) {
+	filter := NotificationFilter{Repo: req.Repo}
+	if req.Reason != "" {
+		reasons, ok := resolveSpokenList(req.Reason, notificationReasons)
+		if !ok {
+			return filter, fmt.Errorf("Sorry, I can't filter notifications by %s.", req.Reason)
+		}
+		filter.Reasons = reasons
+	}
+	if req.Type != "" {
+		types, ok := resolveSpokenList(req.Type, notificationTypes)
+		if !ok {
+			return filter, fmt.Errorf("Sorry, I can't filter notifications by %s.", req.Type)
+		}
+		filter.Types = types
+	}
+	return filter, nil
+}
+
+// The filter for a notifications request. Slots override the user's saved filter one field at a time.
+func notificationFilter(ctx context.Context, req *IntentRequest) (NotificationFilter, error) {
+	requested, err := resolveNotificationFilter(req)
+	if err != nil {
+		return requested, err
+	}
+
+	prefs := preferencesFromContext(ctx)
+	filter := NotificationFilter{prefs.NotificationReasons, prefs.NotificationTypes, prefs.NotificationRepo}
+	if len(requested.Reasons) > 0 {
+		filter.Reasons = requested.Reasons
+	}
+	if len(requested.Types) > 0 {
+		filter.Types = requested.Types
+	}
+	if requested.Repo != "" {
+		filter.Repo = requested.Repo
+	}
+	return filter, nil
+}
+
+func (filter NotificationFilter) empty() bool {
+	return len(filter.Reasons) == 0 && len(filter.Types) == 0 && filter.Repo == ""
+}
+
+// Only ask for participating threads when every reason wanted is one of those.
+func (filter NotificationFilter) listOptions() *github.NotificationListOptions {
+	opt := &github.NotificationListOptions{ListOptions: github.ListOptions{PerPage: notificationsPerPage}}
+	opt.Participating = len(filter.Reasons) > 0
+	for _, reason := range filter.Reasons {
+		opt.Participating = opt.Participating && participatingReasons[reason]
+	}
+	return opt
+}
+
+func (filter NotificationFilter) matches(notification *github.Notification) bool {
+	if len(filter.Reasons) > 0 && !containsString(filter.Reasons, notification.GetReason()) {
+		return false
+	}
+	if len(filter.Types) > 0 && !containsString(filter.Types, notification.Subject.GetType()) {
+		return false
+	}
+	if filter.Repo != "" {
+		want := normalizeName(filter.Repo)
+		repo := notification.GetRepository()
+		if normalizeName(repo.GetName()) != want && normalizeName(repo.GetFullName()) != want {
+			return false
+		}
+	}
+	return true
+}
+
+func (filter NotificationFilter) apply(notifications []*github.Notification) []*github.Notification {
+	var matched []*github.Notification
+	for _, notification := range notifications {
+		if filter.matches(notification) {
+			matched = append(matched, notification)
+		}
+	}
+	return matched
+}
+
+// Say what the filter lets through, like "mentions on pull requests in kubernetes".
+func (filter NotificationFilter) describe() string {
+	noun := "notifications"
+	if len(filter.Reasons) > 0 {
+		noun = joinWords(spokenNames(filter.Reasons, reasonNames))
+	}
+	if len(filter.Types) > 0 {
+		noun += " on " + joinWords(spokenNames(filter.Types, typeNames))
+	}
+	if filter.Repo != "" {
+		noun += " in " + filter.Repo
+	}
+	return noun
+}
+
+func spokenNames(values []string, names map[string]string) []string {
+	var spoken []string
+	for _, value := range values {
+		if name, ok := names[value]; ok {
+			spoken = append(spoken, name)
+		} else {
+			spoken = append(spoken, value)
+		}
+	}
+	return spoken
+}
+
+// What the user has to do with a thread, said after its subject, like "a pull request you're reviewing"
+var reasonClauses = map[string]string{
+	"review_requested": "you're reviewing",
+	"mention":          "you were mentioned in",
+	"team_mention":     "your team was mentioned in",
+	"assign":           "assigned to you",
+	"author":           "you opened",
+	"comment":          "you commented on",
+	"state_change":     "you changed",
+	"subscribed":       "you're watching",
+	"manual":           "you subscribed to",
+	"ci_activity":      "with new CI activity",
+}
+
+// Turn a subject type like "PullRequest" into "pull request".
+func splitCamelCase(s string) string {
+	var words []string
+	start := 0
+	for i, r := range s {
+		if i > start && unicode.IsUpper(r) {
+			words = append(words, s[start:i])
+			start = i
+		}
+	}
+	if start < len(s) {
+		words = append(words, s[start:])
+	}
+	return strings.ToLower(strings.Join(words, " "))
+}
+
+func withArticle(noun string) string {
+	if noun != "" && strings.ContainsRune("aeiou", rune(noun[0])) {
+		return "an " + noun
+	}
+	return "a " + noun
+}
+
+// Say when something happened, like "5 minutes ago", "yesterday" or "on March 3". Days are
+// counted in loc, so late night updates don't land on the wrong day.
+func relativeTime(then, now time.Time, loc *time.Location) string {
+	switch d := now.Sub(then); {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return pluralize(int(d/time.Minute), "minute", "minutes") + " ago"
+	case d < 12*time.Hour:
+		return pluralize(int(d/time.Hour), "hour", "hours") + " ago"
+	}
+
+	switch when := sinceWhen(then, now, loc); when {
+	case "earlier today", "yesterday":
+		return when
+	default:
+		return "on " + when
+	}
+}
+
+// Describe a notification, like "In kubernetes/kubernetes, a pull request you're reviewing was
+// updated 2 hours ago: Fix the scheduler". Brief descriptions leave out the reason and time.
+func describeNotification(notification *github.Notification, now time.Time, loc *time.Location, brief bool) string {
+	repo := notification.GetRepository().GetFullName()
+	title := notification.Subject.GetTitle()
+	if brief {
+		return fmt.Sprintf("In %s: %s", repo, title)
+	}
+
+	subject := withArticle(splitCamelCase(notification.Subject.GetType()))
+	if clause, ok := reasonClauses[notification.GetReason()]; ok {
+		subject += " " + clause
+	}
+
+	updated := "was updated"
+	if !notification.GetUpdatedAt().IsZero() {
+		updated += " " + relativeTime(notification.GetUpdatedAt(), now, loc)
+	}
+	return fmt.Sprintf("In %s, %s %s: %s", repo, subject, updated, title)
+}
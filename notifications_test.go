@@ -0,0 +1,210 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/github"
+)
+
+func Test_resolveSpokenList(t *testing.T) {
+	tests := []struct {
+		name   string
+		spoken string
+		want   []string
+		wantOk bool
+	}{
+		{"Single", "mentions", []string{"mention"}, true},
+		{"And", "mentions and review requests", []string{"mention", "review_requested"}, true},
+		{"API values", "mention,review_requested", []string{"mention", "review_requested"}, true},
+		{"Duplicates", "builds and CI activity", []string{"ci_activity"}, true},
+		{"Unknown", "mentions and spam", nil, false},
+		{"Empty", "", nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := resolveSpokenList(tt.spoken, notificationReasons)
+			if !reflect.DeepEqual(got, tt.want) || ok != tt.wantOk {
+				t.Errorf("resolveSpokenList() = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+}
+
+func Test_NotificationFilter_matches(t *testing.T) {
+	notification := &github.Notification{
+		Reason:     github.String("mention"),
+		Subject:    &github.NotificationSubject{Type: github.String("PullRequest")},
+		Repository: &github.Repository{Name: github.String("kubernetes"), FullName: github.String("kubernetes/kubernetes")},
+	}
+	tests := []struct {
+		name   string
+		filter NotificationFilter
+		want   bool
+	}{
+		{"Empty", NotificationFilter{}, true},
+		{"Reason", NotificationFilter{Reasons: []string{"assign", "mention"}}, true},
+		{"WrongReason", NotificationFilter{Reasons: []string{"ci_activity"}}, false},
+		{"Type", NotificationFilter{Types: []string{"PullRequest"}}, true},
+		{"WrongType", NotificationFilter{Types: []string{"Release"}}, false},
+		{"RepoName", NotificationFilter{Repo: "Kubernetes"}, true},
+		{"RepoFullName", NotificationFilter{Repo: "kubernetes/kubernetes"}, true},
+		{"WrongRepo", NotificationFilter{Repo: "minikube"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.matches(notification); got != tt.want {
+				t.Errorf("NotificationFilter.matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_GithubNotifications_sessionState(t *testing.T) {
+	notifications := []*github.Notification{{ID: github.String("1")}, {ID: github.String("2")}}
+	tests := []struct {
+		name string
+		not  GithubNotifications
+		want bool
+	}{
+		{"All", GithubNotifications{notifications, NotificationFilter{}, 0, false}, false},
+		{"Filtered", GithubNotifications{notifications, NotificationFilter{Repo: "go"}, 0, false}, true},
+		{"Skipped", GithubNotifications{notifications, NotificationFilter{}, 12, false}, true},
+		{"MorePages", GithubNotifications{notifications, NotificationFilter{}, 0, true}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := tt.not.sessionState(testContext())
+			if state.NotificationsPartial != tt.want {
+				t.Errorf("GithubNotifications.sessionState().NotificationsPartial = %v, want %v", state.NotificationsPartial, tt.want)
+			}
+			if ids := sessionRefs(state, NotificationsIntent); !reflect.DeepEqual(ids, []string{"1", "2"}) {
+				t.Errorf("sessionRefs() = %v, want [1 2]", ids)
+			}
+		})
+	}
+}
+
+func Test_markAllReadQuestion(t *testing.T) {
+	one := []*github.Notification{{ID: github.String("1")}}
+	two := []*github.Notification{{ID: github.String("1")}, {ID: github.String("2")}}
+	tests := []struct {
+		name    string
+		session *SessionState
+		want    string
+	}{
+		{"NothingRead", &SessionState{}, "Mark all of your unread notifications as read?"},
+		{"AllOfOne", (&GithubNotifications{one, NotificationFilter{}, 0, false}).sessionState(testContext()), "Mark your only unread notification as read?"},
+		{"AllOfTwo", (&GithubNotifications{two, NotificationFilter{}, 0, false}).sessionState(testContext()), "Mark all 2 of your unread notifications as read?"},
+		{"OneOfSome", (&GithubNotifications{one, NotificationFilter{}, 3, false}).sessionState(testContext()), "Mark just that notification as read? The others stay unread."},
+		{"TwoOfSome", (&GithubNotifications{two, NotificationFilter{Repo: "go"}, 0, false}).sessionState(testContext()), "Mark just those 2 notifications as read? The others stay unread."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := markAllReadQuestion(tt.session); got != tt.want {
+				t.Errorf("markAllReadQuestion() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_NotificationFilter_describe(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter NotificationFilter
+		want   string
+	}{
+		{"Empty", NotificationFilter{}, "notifications"},
+		{"Reasons", NotificationFilter{Reasons: []string{"mention", "review_requested"}}, "mentions and review requests"},
+		{"Everything", NotificationFilter{[]string{"mention"}, []string{"PullRequest"}, "kubernetes"}, "mentions on pull requests in kubernetes"},
+		{"TypeAndRepo", NotificationFilter{Types: []string{"Release"}, Repo: "go"}, "notifications on releases in go"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.describe(); got != tt.want {
+				t.Errorf("NotificationFilter.describe() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_splitCamelCase(t *testing.T) {
+	tests := []struct {
+		name string
+		arg  string
+		want string
+	}{
+		{"Two words", "PullRequest", "pull request"},
+		{"One word", "Issue", "issue"},
+		{"Many words", "RepositoryVulnerabilityAlert", "repository vulnerability alert"},
+		{"Empty", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := splitCamelCase(tt.arg); got != tt.want {
+				t.Errorf("splitCamelCase() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_relativeTime(t *testing.T) {
+	now := time.Date(2018, 3, 10, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		then time.Time
+		loc  *time.Location
+		want string
+	}{
+		{"Just now", now.Add(-30 * time.Second), time.UTC, "just now"},
+		{"Minute", now.Add(-time.Minute), time.UTC, "1 minute ago"},
+		{"Minutes", now.Add(-45 * time.Minute), time.UTC, "45 minutes ago"},
+		{"Hours", now.Add(-2 * time.Hour), time.UTC, "2 hours ago"},
+		{"Just under 12 hours", now.Add(-11*time.Hour - 59*time.Minute - time.Second), time.UTC, "11 hours ago"},
+		{"Earlier today", now.Add(-13 * time.Hour), time.FixedZone("AEST", 10*60*60), "earlier today"},
+		{"Yesterday", now.Add(-20 * time.Hour), time.UTC, "yesterday"},
+		{"Weekday", now.Add(-3 * 24 * time.Hour), time.UTC, "on Wednesday"},
+		{"Date", now.Add(-30 * 24 * time.Hour), time.UTC, "on February 8"},
+		{"Yesterday in UTC", now.Add(-12*time.Hour - 30*time.Minute), time.UTC, "yesterday"},
+		{"Today in Paris", now.Add(-12*time.Hour - 30*time.Minute), time.FixedZone("CET", 60*60), "earlier today"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := relativeTime(tt.then, now, tt.loc); got != tt.want {
+				t.Errorf("relativeTime() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_describeNotification(t *testing.T) {
+	now := time.Date(2018, 3, 10, 12, 0, 0, 0, time.UTC)
+	updated := now.Add(-2 * time.Hour)
+	notification := func(reason, subjectType string) *github.Notification {
+		return &github.Notification{
+			Reason:     github.String(reason),
+			Subject:    &github.NotificationSubject{Type: github.String(subjectType), Title: github.String("Fix the scheduler")},
+			Repository: &github.Repository{FullName: github.String("kubernetes/kubernetes")},
+			UpdatedAt:  &updated,
+		}
+	}
+	tests := []struct {
+		name         string
+		notification *github.Notification
+		brief        bool
+		want         string
+	}{
+		{"Review", notification("review_requested", "PullRequest"), false, "In kubernetes/kubernetes, a pull request you're reviewing was updated 2 hours ago: Fix the scheduler"},
+		{"Article", notification("assign", "Issue"), false, "In kubernetes/kubernetes, an issue assigned to you was updated 2 hours ago: Fix the scheduler"},
+		{"Unknown reason", notification("something_new", "Release"), false, "In kubernetes/kubernetes, a release was updated 2 hours ago: Fix the scheduler"},
+		{"Brief", notification("mention", "PullRequest"), true, "In kubernetes/kubernetes: Fix the scheduler"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := describeNotification(tt.notification, now, time.UTC, tt.brief); got != tt.want {
+				t.Errorf("describeNotification() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,333 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/github"
+	"google.golang.org/appengine/datastore"
+)
+
+const (
+	OrgDigestIntent = "org_digest_intent"
+	SetOrgIntent    = "set_org_intent"
+
+	orgSnapshotKind   = "OrgSnapshot" // Use to store in Cloud Datastore
+	maxOrgMemberPages = 3             // Members to compare, 100 per page
+	maxOrgRepos       = 30            // Most recently pushed repos to check for releases
+	maxOrgNamesRead   = 5             // New members or releases read by name
+)
+
+// An org's members the last time the user heard its digest, so the next one can say who's new
+type OrgSnapshot struct {
+	Members []string
+	TakenAt time.Time
+}
+
+type OrgDigest struct {
+	org        string
+	day        string // The day covered, like "yesterday"
+	member     bool   // Whether the user belongs to the org, or only sees its public side
+	merged     int    // -1 if unknown
+	opened     int    // -1 if unknown
+	newMembers []string
+	firstTime  bool // No earlier member list to compare against
+	releases   []repoRelease
+	unseen     []string // Parts of the digest that couldn't be read, like "members"
+}
+
+// Snapshots are kept per platform user and org, since members visible depend on who's asking.
+func orgSnapshotKey(ctx context.Context, userID, org string) *datastore.Key {
+	return datastore.NewKey(ctx, orgSnapshotKind, userID+"/"+strings.ToLower(org), 0, nil)
+}
+
+func loadOrgSnapshot(ctx context.Context, userID, org string) *OrgSnapshot {
+	if cliFromContext(ctx) != nil || userID == "" {
+		return nil
+	}
+
+	snapshot := &OrgSnapshot{}
+	if err := datastore.Get(ctx, orgSnapshotKey(ctx, userID, org), snapshot); err != nil {
+		if err != datastore.ErrNoSuchEntity {
+			debugf(ctx, "Failed to load org snapshot for %s: %v", org, err)
+		}
+		return nil
+	}
+	return snapshot
+}
+
+func saveOrgSnapshot(ctx context.Context, userID, org string, snapshot *OrgSnapshot) {
+	if cliFromContext(ctx) != nil || userID == "" {
+		return
+	}
+
+	if _, err := datastore.Put(ctx, orgSnapshotKey(ctx, userID, org), snapshot); err != nil {
+		debugf(ctx, "Failed to save org snapshot for %s: %v", org, err)
+	}
+}
+
+// Pick the org: the one spoken, the user's default, or their only org. Returns a question to
+// ask instead if it can't tell.
+func resolveOrg(ctx context.Context, client *github.Client, spoken string) (org, question string, err error) {
+	orgs, _, err := client.Organizations.List(ctx, "", nil)
+	if err != nil {
+		return "", "", err
+	}
+
+	if spoken == "" {
+		switch len(orgs) {
+		case 0:
+			return "", "You're not in any orgs I can see. Ask for an org by name, like the golang org.", nil
+		case 1:
+			return orgs[0].GetLogin(), "", nil
+		}
+
+		var logins []string
+		for _, o := range orgs {
+			logins = append(logins, o.GetLogin())
+		}
+		return "", fmt.Sprintf("Which org? You're in %s.", joinWords(logins)), nil
+	}
+
+	for _, o := range orgs {
+		if normalizeName(o.GetLogin()) == normalizeName(spoken) {
+			return o.GetLogin(), "", nil
+		}
+	}
+	return strings.Replace(strings.TrimSpace(spoken), " ", "-", -1), "", nil
+}
+
+// Members that weren't there last time.
+func newOrgMembers(previous, current []string) []string {
+	seen := map[string]bool{}
+	for _, login := range previous {
+		seen[login] = true
+	}
+
+	var added []string
+	for _, login := range current {
+		if !seen[login] {
+			added = append(added, login)
+		}
+	}
+	return added
+}
+
+func listOrgMembers(ctx context.Context, client *github.Client, org string) ([]string, error) {
+	opt := &github.ListMembersOptions{ListOptions: github.ListOptions{PerPage: 100}}
+	var logins []string
+	for page := 0; page < maxOrgMemberPages; page++ {
+		members, resp, err := client.Organizations.ListMembers(ctx, org, opt)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, member := range members {
+			logins = append(logins, member.GetLogin())
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opt.Page = resp.NextPage
+	}
+	return logins, nil
+}
+
+// Count search results, like pull requests merged in a time range.
+func countIssues(ctx context.Context, client *github.Client, query string) (int, error) {
+	result, _, err := client.Search.Issues(ctx, query, &github.SearchOptions{ListOptions: github.ListOptions{PerPage: 1}})
+	if err != nil {
+		return -1, err
+	}
+	return result.GetTotal(), nil
+}
+
+// Releases published in [start, end) across the org's most recently pushed repos.
+func listOrgReleases(ctx context.Context, client *github.Client, org string, start, end time.Time) ([]repoRelease, error) {
+	opt := &github.RepositoryListByOrgOptions{ListOptions: github.ListOptions{PerPage: 100}}
+	repos, _, err := client.Repositories.ListByOrg(ctx, org, opt)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(repos, func(i, j int) bool {
+		return repos[i].GetPushedAt().After(repos[j].GetPushedAt().Time)
+	})
+
+	var mu sync.Mutex
+	var found []repoRelease
+	var wg sync.WaitGroup
+	for _, repo := range repos[:minInt(len(repos), maxOrgRepos)] {
+		wg.Add(1)
+		go func(repo *github.Repository) {
+			defer wg.Done()
+			releases, _, err := client.Repositories.ListReleases(ctx, org, repo.GetName(), &github.ListOptions{PerPage: releasesPerRepo})
+			if err != nil {
+				debugf(ctx, "Failed to list releases for %s: %v", repo.GetFullName(), err)
+				return
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			for _, release := range newReleases(releases, start) {
+				if release.GetPublishedAt().Before(end) {
+					found = append(found, repoRelease{repo.GetFullName(), release})
+				}
+			}
+		}(repo)
+	}
+	wg.Wait()
+
+	sort.SliceStable(found, func(i, j int) bool {
+		return found[i].release.GetPublishedAt().After(found[j].release.GetPublishedAt().Time)
+	})
+	return found, nil
+}
+
+// Yesterday, midnight to midnight in loc.
+func yesterday(now time.Time, loc *time.Location) (start, end time.Time) {
+	now = now.In(loc)
+	end = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	return end.AddDate(0, 0, -1), end
+}
+
+func getOrgDigest(ctx context.Context, req *IntentRequest) (FulfillmentBuilder, error) {
+	client := createGithubClient(ctx, req.AccessToken)
+	spoken := req.Org
+	if spoken == "" {
+		spoken = preferencesFromContext(ctx).Org
+	}
+	org, question, err := resolveOrg(ctx, client, spoken)
+	if err != nil {
+		return nil, err
+	} else if question != "" {
+		return &SpokenResponse{question, question, nil}, nil
+	}
+
+	if _, _, err := client.Organizations.Get(ctx, org); hasStatus(err, http.StatusNotFound) {
+		reply := fmt.Sprintf("I couldn't find an org called %s.", org)
+		return &SpokenResponse{reply, reply, nil}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	digest := &OrgDigest{org: org, day: "yesterday", merged: -1, opened: -1}
+	if _, _, err := client.Organizations.GetOrgMembership(ctx, "", org); err == nil {
+		digest.member = true
+	} else if !hasStatus(err, http.StatusNotFound) && !hasStatus(err, http.StatusForbidden) {
+		return nil, err
+	}
+
+	start, end := yesterday(time.Now(), preferencesFromContext(ctx).location())
+	timeRange := start.Format(time.RFC3339) + ".." + end.Add(-time.Second).Format(time.RFC3339)
+
+	var mu sync.Mutex
+	unseen := func(part string, err error) {
+		debugf(ctx, "Org digest for %s couldn't read %s: %v", org, part, err)
+		mu.Lock()
+		digest.unseen = append(digest.unseen, part)
+		mu.Unlock()
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(4)
+	go func() {
+		defer wg.Done()
+		var err error
+		if digest.merged, err = countIssues(ctx, client, fmt.Sprintf("org:%s is:pr is:merged merged:%s", org, timeRange)); err != nil {
+			unseen("merged pull requests", err)
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		var err error
+		if digest.opened, err = countIssues(ctx, client, fmt.Sprintf("org:%s is:issue created:%s", org, timeRange)); err != nil {
+			unseen("new issues", err)
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		members, err := listOrgMembers(ctx, client, org)
+		if err != nil {
+			unseen("members", err)
+			return
+		}
+
+		previous := loadOrgSnapshot(ctx, req.UserID, org)
+		if previous == nil {
+			digest.firstTime = true
+		} else {
+			digest.newMembers = newOrgMembers(previous.Members, members)
+		}
+		saveOrgSnapshot(ctx, req.UserID, org, &OrgSnapshot{members, time.Now()})
+	}()
+	go func() {
+		defer wg.Done()
+		releases, err := listOrgReleases(ctx, client, org, start, end)
+		if err != nil {
+			unseen("releases", err)
+			return
+		}
+		digest.releases = releases
+	}()
+	wg.Wait()
+
+	sort.Strings(digest.unseen) // Goroutines finish in any order
+	return digest, nil
+}
+
+func (digest *OrgDigest) describe() string {
+	var parts []string
+	if !digest.member {
+		parts = append(parts, fmt.Sprintf("You're not a member of %s, so this only covers what's public.", digest.org))
+	}
+
+	var counts []string
+	if digest.merged >= 0 {
+		counts = append(counts, pluralize(digest.merged, "pull request was", "pull requests were")+" merged")
+	}
+	if digest.opened >= 0 {
+		counts = append(counts, pluralize(digest.opened, "issue was", "issues were")+" opened")
+	}
+	if len(counts) > 0 {
+		parts = append(parts, fmt.Sprintf("In %s %s, %s.", digest.org, digest.day, joinWords(counts)))
+	}
+
+	if n := len(digest.releases); n > 0 {
+		var names []string
+		for _, found := range digest.releases[:minInt(n, maxOrgNamesRead)] {
+			names = append(names, found.repo+" "+found.release.GetTagName())
+		}
+		if n > maxOrgNamesRead {
+			names = append(names, pluralize(n-maxOrgNamesRead, "more", "more"))
+		}
+		parts = append(parts, fmt.Sprintf("New releases: %s.", joinWords(names)))
+	} else if !containsString(digest.unseen, "releases") {
+		parts = append(parts, "There were no new releases.")
+	}
+
+	if n := len(digest.newMembers); n > 0 {
+		names := append([]string{}, digest.newMembers[:minInt(n, maxOrgNamesRead)]...)
+		if n > maxOrgNamesRead {
+			names = append(names, pluralize(n-maxOrgNamesRead, "more", "more"))
+		}
+		parts = append(parts, fmt.Sprintf("New since your last digest: %s.", joinWords(names)))
+	} else if digest.firstTime {
+		parts = append(parts, "From next time, I'll also tell you who joined.")
+	}
+
+	if len(digest.unseen) > 0 {
+		parts = append(parts, fmt.Sprintf("I couldn't see %s.", joinWords(digest.unseen)))
+	}
+	return strings.Join(parts, " ")
+}
+
+func (digest *OrgDigest) buildFulfillment(ctx context.Context) *FulfillmentResp {
+	description := digest.describe()
+	debugf(ctx, "Built fulfillment with string: %s", description)
+	return &FulfillmentResp{"<speak>" + description + "</speak>", description}
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/github"
+)
+
+func Test_newOrgMembers(t *testing.T) {
+	tests := []struct {
+		name     string
+		previous []string
+		current  []string
+		want     []string
+	}{
+		{"Joined", []string{"ada", "grace"}, []string{"ada", "grace", "linus"}, []string{"linus"}},
+		{"Left", []string{"ada", "grace"}, []string{"ada"}, nil},
+		{"Same", []string{"ada"}, []string{"ada"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := newOrgMembers(tt.previous, tt.current); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("newOrgMembers() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_yesterday(t *testing.T) {
+	denver, _ := time.LoadLocation("America/Denver")
+	now := time.Date(2018, 3, 10, 3, 0, 0, 0, time.UTC) // Still March 9 in Denver
+	start, end := yesterday(now, denver)
+	if want := time.Date(2018, 3, 8, 0, 0, 0, 0, denver); !start.Equal(want) {
+		t.Errorf("yesterday() start = %v, want %v", start, want)
+	}
+	if want := time.Date(2018, 3, 9, 0, 0, 0, 0, denver); !end.Equal(want) {
+		t.Errorf("yesterday() end = %v, want %v", end, want)
+	}
+}
+
+func Test_OrgDigest_describe(t *testing.T) {
+	release := repoRelease{"golang/tools", &github.RepositoryRelease{TagName: github.String("v0.1.0")}}
+	tests := []struct {
+		name   string
+		digest OrgDigest
+		want   string
+	}{
+		{"Member", OrgDigest{org: "golang", day: "yesterday", member: true, merged: 3, opened: 1, newMembers: []string{"ada"}, releases: []repoRelease{release}},
+			"In golang yesterday, 3 pull requests were merged and 1 issue was opened. New releases: golang/tools v0.1.0. New since your last digest: ada."},
+		{"First time", OrgDigest{org: "golang", day: "yesterday", member: true, merged: 0, opened: 0, firstTime: true},
+			"In golang yesterday, 0 pull requests were merged and 0 issues were opened. There were no new releases. From next time, I'll also tell you who joined."},
+		{"Outsider", OrgDigest{org: "golang", day: "yesterday", merged: 2, opened: -1, unseen: []string{"members", "new issues"}},
+			"You're not a member of golang, so this only covers what's public. In golang yesterday, 2 pull requests were merged. There were no new releases. I couldn't see members and new issues."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.digest.describe(); got != tt.want {
+				t.Errorf("OrgDigest.describe() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	NextIntent     = "next_intent"
+	PreviousIntent = "previous_intent"
+
+	defaultPageSize = 5
+	maxListItems    = 50 // Keeps session state small enough for Alexa and Dialogflow
+)
+
+var errNoList = errors.New("There's no list to pick from. Try asking for your notifications or trending repos first.")
+
+// One entry of a list, already rendered so paging through doesn't need to refetch anything.
+type ListItem struct {
+	Text   string `json:"text"`
+	Speech string `json:"speech"`
+	Ref    string `json:"ref,omitempty"` // What follow ups act on, like a notification thread ID or owner/repo
+}
+
+// A list that's read out a few items at a time. It's stored in the session so "next" and
+// "previous" can page through it on later turns.
+type PagedList struct {
+	Kind         string     `json:"kind"` // Intent that produced the list
+	SpeechHeader string     `json:"speechHeader,omitempty"`
+	TextHeader   string     `json:"textHeader,omitempty"`
+	Empty        string     `json:"empty,omitempty"` // Said instead when there are no items
+	Hint         string     `json:"hint,omitempty"`  // SSML read after the last page, like what to say next
+	Items        []ListItem `json:"items,omitempty"`
+	Page         int        `json:"page"`
+	PageSize     int        `json:"pageSize"`
+}
+
+func newPagedList(ctx context.Context, kind string) *PagedList {
+	return &PagedList{Kind: kind, PageSize: preferencesFromContext(ctx).pageSize()}
+}
+
+func (list *PagedList) add(item ListItem) {
+	if len(list.Items) < maxListItems {
+		list.Items = append(list.Items, item)
+	}
+}
+
+func (list *PagedList) pageCount() int {
+	return (len(list.Items) + list.PageSize - 1) / list.PageSize
+}
+
+func (list *PagedList) hasNextPage() bool {
+	return list.Page+1 < list.pageCount()
+}
+
+// Render items [start, end) as display text and SSML.
+func (list *PagedList) render(start, end int) (text, speech string) {
+	for _, item := range list.Items[start:end] {
+		text += "\n" + item.Text
+		speech += item.Speech
+	}
+	return text, speech
+}
+
+func (list *PagedList) buildFulfillment(ctx context.Context) *FulfillmentResp {
+	if len(list.Items) == 0 {
+		debugf(ctx, "Built fulfillment with string: %s", list.Empty)
+		return &FulfillmentResp{"<speak>" + list.Empty + "</speak>", list.Empty}
+	}
+
+	var text, speech string
+	if list.Page == 0 {
+		text, speech = list.TextHeader, list.SpeechHeader
+	}
+
+	start := list.Page * list.PageSize
+	end := minInt(start+list.PageSize, len(list.Items))
+	pageText, pageSpeech := list.render(start, end)
+	text += pageText
+	speech += pageSpeech
+
+	if list.hasNextPage() {
+		text += "\n\nSay next for more."
+		speech += "<p>Want to hear more?</p>"
+	} else if !preferencesFromContext(ctx).brief() {
+		speech += list.Hint
+	}
+
+	debugf(ctx, "Built fulfillment with string: %s", speech)
+	return &FulfillmentResp{"<speak>" + speech + "</speak>", text}
+}
+
+// Keep the list around for paging and follow ups as long as there's something in it.
+func (list *PagedList) sessionState(ctx context.Context) *SessionState {
+	if len(list.Items) == 0 {
+		return nil
+	}
+	return &SessionState{List: list}
+}
+
+// Move delta pages through the list held in the session.
+func pageList(session *SessionState, delta int) FulfillmentBuilder {
+	if session.List == nil || len(session.List.Items) == 0 {
+		reply := "There's no list to page through. Try asking for your notifications or trending repos."
+		return &SpokenResponse{reply, reply, nil}
+	}
+
+	page := session.List.Page + delta
+	if page < 0 {
+		reply := "You're already at the start of the list."
+		return &SpokenResponse{reply, reply, session}
+	} else if page >= session.List.pageCount() {
+		reply := "That's the end of the list."
+		return &SpokenResponse{reply, reply, session}
+	}
+
+	state := *session
+	list := *session.List
+	list.Page = page
+	state.List = &list
+	state.PendingAction = ""
+	return &pageResponse{&list, &state}
+}
+
+// A page of a list read on a later turn, carrying the rest of the session along with it
+type pageResponse struct {
+	list  *PagedList
+	state *SessionState
+}
+
+func (resp *pageResponse) buildFulfillment(ctx context.Context) *FulfillmentResp {
+	return resp.list.buildFulfillment(ctx)
+}
+
+func (resp *pageResponse) sessionState(ctx context.Context) *SessionState {
+	return resp.state
+}
+
+// The refs of the items in the session's list, if it was produced by the intent kind.
+func sessionRefs(session *SessionState, kind string) []string {
+	if session.List == nil || session.List.Kind != kind {
+		return nil
+	}
+
+	var refs []string
+	for _, item := range session.List.Items {
+		refs = append(refs, item.Ref)
+	}
+	return refs
+}
+
+// Format an issue or pull request reference, like "owner/repo#12"
+func issueRef(repoFullName string, number int) string {
+	return fmt.Sprintf("%s#%d", repoFullName, number)
+}
+
+// Split "owner/repo" into its parts.
+func parseRepoRef(ref string) (owner, repo string, ok bool) {
+	parts := strings.SplitN(ref, "/", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", false
+	}
+	return parts[0], parts[1], true
+}
+
+// Split "owner/repo#12" into its parts.
+func parseIssueRef(ref string) (owner, repo string, number int, ok bool) {
+	i := strings.LastIndex(ref, "#")
+	if i < 0 {
+		return "", "", 0, false
+	}
+
+	number, err := strconv.Atoi(ref[i+1:])
+	if err != nil {
+		return "", "", 0, false
+	}
+
+	owner, repo, ok = parseRepoRef(ref[:i])
+	if !ok {
+		return "", "", 0, false
+	}
+	return owner, repo, number, true
+}
+
+// Find the item the user means by "number 3" in the session's list. Numbers count from the
+// start of the list, not the current page, since that's how they were read out.
+func resolveOrdinal(session *SessionState, number string) (*ListItem, error) {
+	if session.List == nil || len(session.List.Items) == 0 {
+		return nil, errNoList
+	}
+
+	i, err := strconv.Atoi(number)
+	if err != nil || i < 1 || i > len(session.List.Items) {
+		return nil, fmt.Errorf("Please pick a number between 1 and %d.", len(session.List.Items))
+	}
+	return &session.List.Items[i-1], nil
+}
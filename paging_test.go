@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+func testContext() context.Context {
+	return withCLI(context.Background(), &cliOptions{logOutput: ioutil.Discard})
+}
+
+func testList(count int) *PagedList {
+	list := newPagedList(testContext(), NotificationsIntent)
+	list.SpeechHeader = "<p>Header</p>"
+	list.Hint = "<p>Hint</p>"
+	for i := 1; i <= count; i++ {
+		list.add(ListItem{fmt.Sprintf("#%d", i), fmt.Sprintf("<p>#%d</p>", i), fmt.Sprint(i)})
+	}
+	return list
+}
+
+func Test_PagedList_buildFulfillment(t *testing.T) {
+	ctx := testContext()
+	tests := []struct {
+		name  string
+		count int
+		page  int
+		want  string
+	}{
+		{"Single page", 3, 0, "<speak><p>Header</p><p>#1</p><p>#2</p><p>#3</p><p>Hint</p></speak>"},
+		{"First of two pages", 7, 0, "<speak><p>Header</p><p>#1</p><p>#2</p><p>#3</p><p>#4</p><p>#5</p><p>Want to hear more?</p></speak>"},
+		{"Last page", 7, 1, "<speak><p>#6</p><p>#7</p><p>Hint</p></speak>"},
+		{"Empty", 0, 0, "<speak></speak>"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			list := testList(tt.count)
+			list.Page = tt.page
+			if got := list.buildFulfillment(ctx).Speech; got != tt.want {
+				t.Errorf("buildFulfillment() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_pageList(t *testing.T) {
+	ctx := testContext()
+	tests := []struct {
+		name    string
+		session *SessionState
+		delta   int
+		want    string
+	}{
+		{"No list", &SessionState{}, 1, "no list"},
+		{"Next page", &SessionState{List: testList(7)}, 1, "<p>#6</p>"},
+		{"Before the start", &SessionState{List: testList(7)}, -1, "start of the list"},
+		{"Past the end", &SessionState{List: testList(3)}, 1, "end of the list"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := pageList(tt.session, tt.delta).buildFulfillment(ctx).Speech; !strings.Contains(got, tt.want) {
+				t.Errorf("pageList() = %v, want it to contain %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_pageListKeepsSession(t *testing.T) {
+	session := &SessionState{List: testList(7)}
+	state := nextSessionState(testContext(), pageList(session, 1))
+	if state == nil || state.List.Page != 1 {
+		t.Fatalf("pageList() state = %+v, want page 1", state)
+	}
+	if session.List.Page != 0 {
+		t.Errorf("pageList() modified the incoming session")
+	}
+}
@@ -0,0 +1,269 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/appengine/datastore"
+)
+
+const (
+	SetLanguageIntent   = "set_language_intent"
+	SetListLengthIntent = "set_list_length_intent"
+	SetVerbosityIntent  = "set_verbosity_intent"
+	SetTimezoneIntent   = "set_timezone_intent"
+	PreferencesIntent   = "preferences_intent"
+
+	SetNotificationFilterIntent = "set_notification_filter_intent"
+
+	VerbosityBrief  = "brief"
+	VerbosityNormal = "normal"
+
+	maxListLength   = 10
+	preferencesKind = "Preferences" // Use to store in Cloud Datastore
+)
+
+// Preferences are remembered per platform user and fill in any slot the user leaves empty.
+type Preferences struct {
+	Lang       string // Language as the user said it, like "Rust"
+	ListLength int    // Trending repos to read, and list items per page
+	Verbosity  string
+	Timezone   string // IANA name, like "America/Denver"
+
+	// Default notification filter, see NotificationFilter
+	NotificationReasons []string
+	NotificationTypes   []string
+	NotificationRepo    string
+
+	PriorityRepos []string // Repos whose notifications are read first, most recently added first
+
+	Org string // Organization for the org digest
+}
+
+// Spoken names for common timezones. Anything else must be an IANA name.
+var spokenTimezones = map[string]string{
+	"pacific":  "America/Los_Angeles",
+	"mountain": "America/Denver",
+	"central":  "America/Chicago",
+	"eastern":  "America/New_York",
+	"utc":      "UTC",
+	"gmt":      "UTC",
+	"london":   "Europe/London",
+	"paris":    "Europe/Paris",
+	"berlin":   "Europe/Berlin",
+	"india":    "Asia/Kolkata",
+	"tokyo":    "Asia/Tokyo",
+	"sydney":   "Australia/Sydney",
+}
+
+type preferencesContextKey struct{}
+
+func withPreferences(ctx context.Context, prefs *Preferences) context.Context {
+	return context.WithValue(ctx, preferencesContextKey{}, prefs)
+}
+
+// Never returns nil, so callers can use the zero value defaults.
+func preferencesFromContext(ctx context.Context) *Preferences {
+	if prefs, ok := ctx.Value(preferencesContextKey{}).(*Preferences); ok && prefs != nil {
+		return prefs
+	}
+	return &Preferences{}
+}
+
+func (prefs *Preferences) brief() bool {
+	return prefs.Verbosity == VerbosityBrief
+}
+
+func (prefs *Preferences) pageSize() int {
+	if prefs.ListLength > 0 {
+		return prefs.ListLength
+	}
+	return defaultPageSize
+}
+
+func (prefs *Preferences) location() *time.Location {
+	if loc, err := time.LoadLocation(prefs.Timezone); err == nil && prefs.Timezone != "" {
+		return loc
+	}
+	return time.UTC
+}
+
+// Platform user IDs are prefixed with the platform so they can't collide in the store.
+func platformUserID(platform, id string) string {
+	if id == "" {
+		return ""
+	}
+	return platform + ":" + id
+}
+
+func loadPreferences(ctx context.Context, userID string) *Preferences {
+	if opts := cliFromContext(ctx); opts != nil {
+		if opts.prefs != nil {
+			return opts.prefs
+		}
+		return &Preferences{}
+	}
+
+	prefs := &Preferences{}
+	if userID == "" {
+		return prefs
+	}
+
+	key := datastore.NewKey(ctx, preferencesKind, userID, 0, nil)
+	if err := datastore.Get(ctx, key, prefs); err != nil && err != datastore.ErrNoSuchEntity {
+		debugf(ctx, "Failed to load preferences for %s: %v", userID, err)
+	}
+	return prefs
+}
+
+func savePreferences(ctx context.Context, userID string, prefs *Preferences) error {
+	key := datastore.NewKey(ctx, preferencesKind, userID, 0, nil)
+	_, err := datastore.Put(ctx, key, prefs)
+	return err
+}
+
+func resolveTimezone(spoken string) (string, bool) {
+	name := strings.TrimSuffix(strings.ToLower(strings.TrimSpace(spoken)), " time")
+	if tz, ok := spokenTimezones[name]; ok {
+		return tz, true
+	}
+
+	if spoken == "" {
+		return "", false
+	}
+	if loc, err := time.LoadLocation(spoken); err == nil {
+		return loc.String(), true
+	}
+	return "", false
+}
+
+// Parse a list length, which must be between 1 and maxListLength.
+func resolveListLength(spoken string) (int, bool) {
+	n, err := strconv.Atoi(strings.TrimSpace(spoken))
+	if err != nil || n < 1 || n > maxListLength {
+		return 0, false
+	}
+	return n, true
+}
+
+func resolveVerbosity(spoken string) (string, bool) {
+	switch strings.ToLower(strings.TrimSpace(spoken)) {
+	case "brief", "short", "shorter", "quick", "concise":
+		return VerbosityBrief, true
+	case "normal", "detailed", "long", "longer", "full":
+		return VerbosityNormal, true
+	}
+	return "", false
+}
+
+// Handle the "set my default ..." intents.
+func setPreference(ctx context.Context, req *IntentRequest, client *http.Client) (FulfillmentBuilder, error) {
+	if req.UserID == "" {
+		reply := "I can only remember preferences when you're talking to me through Alexa or the Assistant."
+		return &SpokenResponse{reply, reply, nil}, nil
+	}
+
+	prefs := *preferencesFromContext(ctx)
+	var reply string
+	switch req.Name {
+	case SetLanguageIntent:
+		if extractLang(client, req.Lang) == "" {
+			reply = fmt.Sprintf("Sorry, I don't know the language %s.", req.Lang)
+			return &SpokenResponse{reply, reply, nil}, nil
+		}
+		prefs.Lang = req.Lang
+		reply = fmt.Sprintf("Okay, I'll show %s repos by default.", req.Lang)
+	case SetListLengthIntent:
+		n, ok := resolveListLength(req.Number)
+		if !ok {
+			reply = fmt.Sprintf("Please pick a number between 1 and %d.", maxListLength)
+			return &SpokenResponse{reply, reply, nil}, nil
+		}
+		prefs.ListLength = n
+		reply = fmt.Sprintf("Okay, I'll read %d items at a time.", n)
+	case SetVerbosityIntent:
+		verbosity, ok := resolveVerbosity(req.Verbosity)
+		if !ok {
+			reply = "You can ask me to be brief or detailed."
+			return &SpokenResponse{reply, reply, nil}, nil
+		}
+		prefs.Verbosity = verbosity
+		reply = fmt.Sprintf("Okay, I'll keep things %s.", verbosity)
+	case SetTimezoneIntent:
+		tz, ok := resolveTimezone(req.Timezone)
+		if !ok {
+			reply = fmt.Sprintf("Sorry, I don't know the timezone %s.", req.Timezone)
+			return &SpokenResponse{reply, reply, nil}, nil
+		}
+		prefs.Timezone = tz
+		reply = fmt.Sprintf("Okay, I'll use %s time.", strings.Replace(tz, "_", " ", -1))
+	case SetNotificationFilterIntent:
+		filter, err := resolveNotificationFilter(req)
+		if err != nil {
+			return &SpokenResponse{err.Error(), err.Error(), nil}, nil
+		}
+		prefs.NotificationReasons, prefs.NotificationTypes, prefs.NotificationRepo = filter.Reasons, filter.Types, filter.Repo
+		reply = fmt.Sprintf("Okay, I'll only read your %s.", filter.describe())
+		if filter.empty() {
+			reply = "Okay, I'll read all of your notifications."
+		}
+	case SetOrgIntent:
+		if req.Org == "" {
+			reply = "Which org should I use?"
+			return &SpokenResponse{reply, reply, nil}, nil
+		}
+		prefs.Org = req.Org
+		reply = fmt.Sprintf("Okay, your org digest will cover %s.", req.Org)
+	case SetPriorityRepoIntent:
+		if req.Repo == "" {
+			reply = "Which repo should I read notifications from first?"
+			return &SpokenResponse{reply, reply, nil}, nil
+		}
+		prefs.PriorityRepos = addPriorityRepo(prefs.PriorityRepos, req.Repo)
+		reply = fmt.Sprintf("Okay, I'll read notifications from %s first.", req.Repo)
+	default:
+		return nil, errUnknownIntent
+	}
+
+	if err := savePreferences(ctx, req.UserID, &prefs); err != nil {
+		return nil, err
+	}
+	return &SpokenResponse{reply, reply, nil}, nil
+}
+
+// Read back what's been set.
+func describePreferences(ctx context.Context) FulfillmentBuilder {
+	prefs := preferencesFromContext(ctx)
+	var parts []string
+	if prefs.Lang != "" {
+		parts = append(parts, fmt.Sprintf("your default language is %s", prefs.Lang))
+	}
+	if prefs.ListLength > 0 {
+		parts = append(parts, fmt.Sprintf("I read %d items at a time", prefs.ListLength))
+	}
+	if prefs.Verbosity != "" {
+		parts = append(parts, fmt.Sprintf("I keep things %s", prefs.Verbosity))
+	}
+	if prefs.Timezone != "" {
+		parts = append(parts, fmt.Sprintf("your timezone is %s", strings.Replace(prefs.Timezone, "_", " ", -1)))
+	}
+	if filter := (NotificationFilter{prefs.NotificationReasons, prefs.NotificationTypes, prefs.NotificationRepo}); !filter.empty() {
+		parts = append(parts, fmt.Sprintf("I only read your %s", filter.describe()))
+	}
+	if prefs.Org != "" {
+		parts = append(parts, fmt.Sprintf("your org is %s", prefs.Org))
+	}
+	if len(prefs.PriorityRepos) > 0 {
+		parts = append(parts, fmt.Sprintf("I read notifications from %s first", joinWords(prefs.PriorityRepos)))
+	}
+
+	reply := "You haven't set any preferences yet. Try saying set my default language to Go."
+	if len(parts) > 0 {
+		reply = "Right now " + joinWords(parts) + "."
+	}
+	return &SpokenResponse{reply, reply, nil}
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"testing"
+)
+
+func Test_resolveTimezone(t *testing.T) {
+	tests := []struct {
+		name   string
+		spoken string
+		want   string
+		wantOK bool
+	}{
+		{"Spoken", "eastern", "America/New_York", true},
+		{"Time suffix", "Pacific time", "America/Los_Angeles", true},
+		{"Padded", " GMT ", "UTC", true},
+		{"City", "tokyo", "Asia/Tokyo", true},
+		{"IANA", "America/Denver", "America/Denver", true},
+		{"IANA with time", "Europe/Paris time", "", false},
+		{"Unknown", "atlantis", "", false},
+		{"Empty", "", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, ok := resolveTimezone(tt.spoken); got != tt.want || ok != tt.wantOK {
+				t.Errorf("resolveTimezone() = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func Test_resolveVerbosity(t *testing.T) {
+	tests := []struct {
+		name   string
+		spoken string
+		want   string
+		wantOK bool
+	}{
+		{"Brief", "brief", VerbosityBrief, true},
+		{"Synonym", "Short", VerbosityBrief, true},
+		{"Concise", " concise ", VerbosityBrief, true},
+		{"Normal", "normal", VerbosityNormal, true},
+		{"Detailed", "detailed", VerbosityNormal, true},
+		{"Unknown", "chatty", "", false},
+		{"Empty", "", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, ok := resolveVerbosity(tt.spoken); got != tt.want || ok != tt.wantOK {
+				t.Errorf("resolveVerbosity() = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func Test_resolveListLength(t *testing.T) {
+	tests := []struct {
+		name   string
+		spoken string
+		want   int
+		wantOK bool
+	}{
+		{"Smallest", "1", 1, true},
+		{"Largest", "10", maxListLength, true},
+		{"Zero", "0", 0, false},
+		{"Too many", "11", 0, false},
+		{"Negative", "-3", 0, false},
+		{"Not a number", "few", 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, ok := resolveListLength(tt.spoken); got != tt.want || ok != tt.wantOK {
+				t.Errorf("resolveListLength() = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+// Only the replies that don't save anything, since saving needs the datastore.
+func Test_setPreferenceRejects(t *testing.T) {
+	tests := []struct {
+		name string
+		req  IntentRequest
+		want string
+	}{
+		{"No user", IntentRequest{Name: SetListLengthIntent, Number: "3"}, "I can only remember preferences when you're talking to me through Alexa or the Assistant."},
+		{"List too long", IntentRequest{Name: SetListLengthIntent, Number: "11", UserID: "alexa:1"}, "Please pick a number between 1 and 10."},
+		{"List too short", IntentRequest{Name: SetListLengthIntent, Number: "0", UserID: "alexa:1"}, "Please pick a number between 1 and 10."},
+		{"Verbosity", IntentRequest{Name: SetVerbosityIntent, Verbosity: "chatty", UserID: "alexa:1"}, "You can ask me to be brief or detailed."},
+		{"Timezone", IntentRequest{Name: SetTimezoneIntent, Timezone: "atlantis", UserID: "alexa:1"}, "Sorry, I don't know the timezone atlantis."},
+		{"Org", IntentRequest{Name: SetOrgIntent, UserID: "alexa:1"}, "Which org should I use?"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			builder, err := setPreference(context.Background(), &tt.req, nil)
+			if err != nil {
+				t.Fatalf("setPreference() error = %v", err)
+			}
+			if got := builder.(*SpokenResponse).text; got != tt.want {
+				t.Errorf("setPreference() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_describePreferences(t *testing.T) {
+	tests := []struct {
+		name  string
+		prefs *Preferences
+		want  string
+	}{
+		{"Nothing set", &Preferences{}, "You haven't set any preferences yet. Try saying set my default language to Go."},
+		{"Some set", &Preferences{Lang: "Rust", ListLength: 3, Timezone: "America/New_York"},
+			"Right now your default language is Rust, I read 3 items at a time and your timezone is America/New York."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := withPreferences(context.Background(), tt.prefs)
+			if got := describePreferences(ctx).(*SpokenResponse).text; got != tt.want {
+				t.Errorf("describePreferences() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
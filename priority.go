@@ -0,0 +1,95 @@
+package main
+
+import (
+	"sort"
+	"time"
+
+	"github.com/google/go-github/github"
+)
+
+const (
+	SetPriorityRepoIntent = "set_priority_repo_intent"
+
+	maxRankedNotifications = 20 // Read at most this many, skipping the rest as lower priority
+	maxPriorityRepos       = 10
+)
+
+// How much each reason matters. Anything not listed, like a new reason, scores 0.
+var reasonWeights = map[string]float64{
+	"review_requested": 10,
+	"mention":          9,
+	"security_alert":   9,
+	"assign":           8,
+	"team_mention":     7,
+	"author":           6,
+	"comment":          5,
+	"manual":           4,
+	"state_change":     3,
+	"subscribed":       2,
+	"ci_activity":      1,
+}
+
+const (
+	participatingWeight = 3 // Bonus for threads the user is part of
+	priorityRepoWeight  = 5 // Bonus for repos the user asked to hear about first
+	recencyWeight       = 4 // Bonus for an update right now, halved after a day
+)
+
+// A NotificationScorer rates a notification. Higher scores are read first.
+type NotificationScorer func(notification *github.Notification, now time.Time) float64
+
+// The default scorer weighs reason, participation, recency and the user's priority repos.
+func priorityScorer(priorityRepos []string) NotificationScorer {
+	return func(notification *github.Notification, now time.Time) float64 {
+		reason := notification.GetReason()
+		score := reasonWeights[reason]
+		if participatingReasons[reason] {
+			score += participatingWeight
+		}
+
+		repo := notification.GetRepository()
+		for _, name := range priorityRepos {
+			want := normalizeName(name)
+			if normalizeName(repo.GetName()) == want || normalizeName(repo.GetFullName()) == want {
+				score += priorityRepoWeight
+				break
+			}
+		}
+
+		if days := now.Sub(notification.GetUpdatedAt()).Hours() / 24; days >= 0 {
+			score += recencyWeight / (1 + days)
+		}
+		return score
+	}
+}
+
+// Sort notifications by score, keeping the API's order for ties, and keep the top max.
+// Returns how many were cut.
+func rankNotifications(notifications []*github.Notification, scorer NotificationScorer, now time.Time, max int) ([]*github.Notification, int) {
+	ranked := make([]*github.Notification, len(notifications))
+	copy(ranked, notifications)
+
+	scores := make(map[*github.Notification]float64, len(ranked))
+	for _, notification := range ranked {
+		scores[notification] = scorer(notification, now)
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return scores[ranked[i]] > scores[ranked[j]]
+	})
+
+	if len(ranked) <= max {
+		return ranked, 0
+	}
+	return ranked[:max], len(ranked) - max
+}
+
+// Add a repo to the front of the user's priority list, dropping the oldest past the limit.
+func addPriorityRepo(repos []string, repo string) []string {
+	updated := []string{repo}
+	for _, existing := range repos {
+		if normalizeName(existing) != normalizeName(repo) && len(updated) < maxPriorityRepos {
+			updated = append(updated, existing)
+		}
+	}
+	return updated
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/github"
+)
+
+func Test_rankNotifications(t *testing.T) {
+	now := time.Date(2018, 3, 10, 12, 0, 0, 0, time.UTC)
+	notification := func(id, reason, repo string, age time.Duration) *github.Notification {
+		updated := now.Add(-age)
+		return &github.Notification{
+			ID:         github.String(id),
+			Reason:     github.String(reason),
+			Repository: &github.Repository{Name: github.String(repo), FullName: github.String("owner/" + repo)},
+			UpdatedAt:  &updated,
+		}
+	}
+	ci := notification("ci", "ci_activity", "api", time.Hour)
+	mention := notification("mention", "mention", "api", 48*time.Hour)
+	review := notification("review", "review_requested", "web", 72*time.Hour)
+	oldSub := notification("oldSub", "subscribed", "docs", 30*24*time.Hour)
+	newSub := notification("newSub", "subscribed", "docs", 0)
+	notifications := []*github.Notification{ci, oldSub, mention, newSub, review}
+
+	tests := []struct {
+		name        string
+		scorer      NotificationScorer
+		max         int
+		want        []*github.Notification
+		wantSkipped int
+	}{
+		{"Default", priorityScorer(nil), 10, []*github.Notification{review, mention, newSub, ci, oldSub}, 0},
+		{"Truncated", priorityScorer(nil), 2, []*github.Notification{review, mention}, 3},
+		{"PriorityRepo", priorityScorer([]string{"docs"}), 3, []*github.Notification{review, mention, newSub}, 2},
+		{"PriorityRepoFullName", priorityScorer([]string{"owner/api"}), 2, []*github.Notification{mention, review}, 3},
+		{"Custom", func(n *github.Notification, now time.Time) float64 { return float64(len(n.GetID())) }, 2, []*github.Notification{mention, oldSub}, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, skipped := rankNotifications(notifications, tt.scorer, now, tt.max)
+			if !reflect.DeepEqual(got, tt.want) || skipped != tt.wantSkipped {
+				var ids []string
+				for _, n := range got {
+					ids = append(ids, n.GetID())
+				}
+				t.Errorf("rankNotifications() = %v, %v, want skipped %v", ids, skipped, tt.wantSkipped)
+			}
+		})
+	}
+}
+
+func Test_addPriorityRepo(t *testing.T) {
+	tests := []struct {
+		name  string
+		repos []string
+		repo  string
+		want  []string
+	}{
+		{"First", nil, "kubernetes", []string{"kubernetes"}},
+		{"Front", []string{"go"}, "kubernetes", []string{"kubernetes", "go"}},
+		{"MovesExisting", []string{"go", "Kubernetes"}, "kubernetes", []string{"kubernetes", "go"}},
+		{"Limit", []string{"1", "2", "3", "4", "5", "6", "7", "8", "9", "10"}, "new", []string{"new", "1", "2", "3", "4", "5", "6", "7", "8", "9"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := addPriorityRepo(tt.repos, tt.repo); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("addPriorityRepo() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
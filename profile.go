@@ -0,0 +1,176 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/go-github/github"
+	"google.golang.org/appengine/datastore"
+)
+
+const (
+	profileSnapshotKind = "ProfileSnapshot" // Use to store in Cloud Datastore
+	maxStarPages        = 3                 // Repos to sum stars over, 100 per page
+)
+
+// The numbers from a user's last profile summary, so the next one can report what changed
+type ProfileSnapshot struct {
+	Followers   int
+	Following   int
+	PublicRepos int
+	Stars       int
+	TakenAt     time.Time
+}
+
+func snapshotKey(ctx context.Context, user *github.User) *datastore.Key {
+	return datastore.NewKey(ctx, profileSnapshotKind, "", user.GetID(), nil)
+}
+
+// Returns nil if there's no earlier snapshot, or nowhere to keep one.
+func loadSnapshot(ctx context.Context, user *github.User) *ProfileSnapshot {
+	if cliFromContext(ctx) != nil {
+		return nil
+	}
+
+	snapshot := &ProfileSnapshot{}
+	if err := datastore.Get(ctx, snapshotKey(ctx, user), snapshot); err != nil {
+		if err != datastore.ErrNoSuchEntity {
+			debugf(ctx, "Failed to load profile snapshot for %s: %v", user.GetLogin(), err)
+		}
+		return nil
+	}
+	return snapshot
+}
+
+func saveSnapshot(ctx context.Context, user *github.User, snapshot *ProfileSnapshot) {
+	if cliFromContext(ctx) != nil {
+		return
+	}
+
+	if _, err := datastore.Put(ctx, snapshotKey(ctx, user), snapshot); err != nil {
+		debugf(ctx, "Failed to save profile snapshot for %s: %v", user.GetLogin(), err)
+	}
+}
+
+// Total stars across the repos the user owns.
+func countStars(ctx context.Context, client *github.Client) (int, error) {
+	opt := &github.RepositoryListOptions{Type: "owner", ListOptions: github.ListOptions{PerPage: 100}}
+	stars := 0
+	for page := 0; page < maxStarPages; page++ {
+		repos, resp, err := client.Repositories.List(ctx, "", opt)
+		if err != nil {
+			return 0, err
+		}
+
+		for _, repo := range repos {
+			stars += repo.GetStargazersCount()
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+		opt.Page = resp.NextPage
+	}
+	return stars, nil
+}
+
+// Fetch the authenticated user's profile along with their last snapshot, and save a new
+// snapshot for next time.
+func fetchProfileSummary(ctx context.Context, client *github.Client) (*ProfileSummary, error) {
+	user, _, err := client.Users.Get(ctx, "") // Get authenticated user
+	if err != nil {
+		return nil, err
+	}
+
+	stars, err := countStars(ctx, client)
+	if err != nil {
+		debugf(ctx, "Failed to count stars for %s: %v", user.GetLogin(), err)
+		stars = -1 // Unknown, so don't report or save a change
+	}
+
+	summary := &ProfileSummary{user: user, previous: loadSnapshot(ctx, user)}
+	summary.current = ProfileSnapshot{
+		Followers:   user.GetFollowers(),
+		Following:   user.GetFollowing(),
+		PublicRepos: user.GetPublicRepos(),
+		Stars:       stars,
+		TakenAt:     time.Now(),
+	}
+
+	if stars < 0 && summary.previous != nil {
+		summary.current.Stars = summary.previous.Stars
+	}
+	saveSnapshot(ctx, user, &summary.current)
+	return summary, nil
+}
+
+func pluralize(n int, singular, plural string) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, singular)
+	}
+	return fmt.Sprintf("%d %s", n, plural)
+}
+
+// Describe what changed between two snapshots, like "3 new followers" and "your repos gained
+// 45 stars". Returns nothing if nothing changed.
+func describeProfileChanges(prev, cur *ProfileSnapshot) []string {
+	var changes []string
+	if diff := cur.Followers - prev.Followers; diff > 0 {
+		changes = append(changes, pluralize(diff, "new follower", "new followers"))
+	} else if diff < 0 {
+		changes = append(changes, "you lost "+pluralize(-diff, "follower", "followers"))
+	}
+
+	if diff := cur.PublicRepos - prev.PublicRepos; diff > 0 {
+		changes = append(changes, pluralize(diff, "new public repo", "new public repos"))
+	} else if diff < 0 {
+		changes = append(changes, pluralize(-diff, "fewer public repo", "fewer public repos"))
+	}
+
+	if diff := cur.Following - prev.Following; diff > 0 {
+		changes = append(changes, "you followed "+pluralize(diff, "more person", "more people"))
+	}
+
+	if cur.Stars < 0 || prev.Stars < 0 {
+		return changes // Couldn't count stars one of the times
+	} else if diff := cur.Stars - prev.Stars; diff > 0 {
+		changes = append(changes, "your repos gained "+pluralize(diff, "star", "stars"))
+	} else if diff < 0 {
+		changes = append(changes, "your repos lost "+pluralize(-diff, "star", "stars"))
+	}
+	return changes
+}
+
+// Say when then was relative to now, like "earlier today", "Tuesday" or "March 3".
+func sinceWhen(then, now time.Time, loc *time.Location) string {
+	then, now = then.In(loc), now.In(loc)
+	thenDay := time.Date(then.Year(), then.Month(), then.Day(), 0, 0, 0, 0, loc)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	days := int(today.Sub(thenDay).Hours()/24 + 0.5) // Round for daylight saving changes
+
+	switch {
+	case days <= 0:
+		return "earlier today"
+	case days == 1:
+		return "yesterday"
+	case days < 7:
+		return then.Format("Monday")
+	default:
+		return then.Format("January 2")
+	}
+}
+
+// The spoken summary of changes since the last snapshot, or empty on first use.
+func (sum *ProfileSummary) changes(ctx context.Context) string {
+	if sum.previous == nil {
+		return ""
+	}
+
+	since := sinceWhen(sum.previous.TakenAt, sum.current.TakenAt, preferencesFromContext(ctx).location())
+	changes := describeProfileChanges(sum.previous, &sum.current)
+	if len(changes) == 0 {
+		return fmt.Sprintf("Nothing has changed on your profile since %s.", since)
+	}
+	return fmt.Sprintf("Since %s, %s.", since, joinWords(changes))
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func Test_describeProfileChanges(t *testing.T) {
+	prev := &ProfileSnapshot{Followers: 10, Following: 5, PublicRepos: 3, Stars: 100}
+	tests := []struct {
+		name string
+		cur  ProfileSnapshot
+		want []string
+	}{
+		{"No changes", *prev, nil},
+		{"Gains", ProfileSnapshot{Followers: 13, Following: 5, PublicRepos: 4, Stars: 145}, []string{"3 new followers", "1 new public repo", "your repos gained 45 stars"}},
+		{"Losses", ProfileSnapshot{Followers: 9, Following: 5, PublicRepos: 3, Stars: 99}, []string{"you lost 1 follower", "your repos lost 1 star"}},
+		{"Following", ProfileSnapshot{Followers: 10, Following: 7, PublicRepos: 3, Stars: 100}, []string{"you followed 2 more people"}},
+		{"Unknown stars", ProfileSnapshot{Followers: 10, Following: 5, PublicRepos: 3, Stars: -1}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := describeProfileChanges(prev, &tt.cur); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("describeProfileChanges() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_sinceWhen(t *testing.T) {
+	now := time.Date(2018, time.March, 15, 9, 0, 0, 0, time.UTC) // A Thursday
+	denver, _ := time.LoadLocation("America/Denver")
+	tests := []struct {
+		name string
+		then time.Time
+		loc  *time.Location
+		want string
+	}{
+		{"Earlier today", now.Add(-2 * time.Hour), time.UTC, "earlier today"},
+		{"Yesterday", now.Add(-20 * time.Hour), time.UTC, "yesterday"},
+		{"This week", now.AddDate(0, 0, -2), time.UTC, "Tuesday"},
+		{"Long ago", now.AddDate(0, 0, -12), time.UTC, "March 3"},
+		{"Timezone moves the day", time.Date(2018, time.March, 15, 5, 0, 0, 0, time.UTC), denver, "yesterday"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sinceWhen(tt.then, now, tt.loc); got != tt.want {
+				t.Errorf("sinceWhen() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,268 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/github"
+)
+
+const reviewRequestsQuery = "is:open is:pr review-requested:@me"
+
+// Open pull requests waiting on a review from the authenticated user, oldest first
+type GithubReviewRequests struct {
+	prs   []github.Issue // The first page of results
+	total int            // Across every page
+}
+
+// Search results don't include the repository object, just its API URL
+// (https://api.github.com/repos/owner/name), so pull "owner/name" out of that.
+func repoFullNameFromURL(repositoryURL string) string {
+	const marker = "/repos/"
+	if i := strings.Index(repositoryURL, marker); i >= 0 {
+		return repositoryURL[i+len(marker):]
+	}
+	return repositoryURL
+}
+
+func getReviewRequests(ctx context.Context, accessToken string) (FulfillmentBuilder, error) {
+	client := createGithubClient(ctx, accessToken)
+	opt := &github.SearchOptions{Sort: "created", Order: "asc"} // Whoever has waited longest goes first
+	result, _, err := client.Search.Issues(ctx, reviewRequestsQuery, opt)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &GithubReviewRequests{result.Issues, result.GetTotal()}, nil
+}
+
+func (reviews *GithubReviewRequests) toList(ctx context.Context) *PagedList {
+	prs := reviews.prs
+	list := newPagedList(ctx, ReviewRequestsIntent)
+	list.Empty = "No pull requests are waiting on your review."
+	switch {
+	case reviews.total == 1:
+		list.SpeechHeader = "<p>1 pull request is waiting on your review:</p>"
+	case reviews.total > len(prs):
+		list.SpeechHeader = fmt.Sprintf("<p>%d pull requests are waiting on your review. Here are the %d that have waited longest:</p>", reviews.total, len(prs))
+	default:
+		list.SpeechHeader = fmt.Sprintf("<p>%d pull requests are waiting on your review:</p>", reviews.total)
+	}
+
+	now := time.Now()
+	for i, pr := range prs {
+		repo := repoFullNameFromURL(pr.GetRepositoryURL())
+		age := timeAgo(pr.GetCreatedAt(), now)
+		list.add(ListItem{
+			Text:   fmt.Sprintf("#%d: %s in %s by %s, opened %s\n%s", i+1, pr.GetTitle(), repo, pr.User.GetLogin(), age, pr.GetHTMLURL()),
+			Speech: fmt.Sprintf("<p>#%d: %s in %s by %s, opened %s</p>", i+1, pr.GetTitle(), repo, pr.User.GetLogin(), age),
+			Ref:    issueRef(repo, pr.GetNumber()),
+		})
+	}
+	return list
+}
+
+func (reviews *GithubReviewRequests) buildFulfillment(ctx context.Context) *FulfillmentResp {
+	return reviews.toList(ctx).buildFulfillment(ctx)
+}
+
+func (reviews *GithubReviewRequests) sessionState(ctx context.Context) *SessionState {
+	return reviews.toList(ctx).sessionState(ctx)
+}
+
+const (
+	myPullRequestsQuery = "is:open is:pr author:@me"
+	myPullRequestsMax   = 10
+
+	ciPassing = "passing"
+	ciFailing = "failing"
+	ciPending = "pending"
+)
+
+// An open pull request by the authenticated user, along with where it stands
+type pullRequestStatus struct {
+	pr               github.Issue
+	repo             string // owner/name
+	ci               string // One of the ci constants, or empty if the repo has no CI
+	approvals        int
+	changesRequested bool
+	mergeable        *bool // Nil when Github hasn't computed it yet
+}
+
+type GithubPullRequestStatuses []*pullRequestStatus
+
+// Combine commit statuses and check runs into a single state. Any failure wins, then
+// anything still running.
+func ciState(status *github.CombinedStatus, runs []*github.CheckRun) string {
+	var states []string
+	if status != nil && status.GetTotalCount() > 0 { // Repos with no statuses report "pending" forever
+		states = append(states, status.GetState())
+	}
+
+	for _, run := range runs {
+		if run.GetStatus() != "completed" {
+			states = append(states, ciPending)
+			continue
+		}
+
+		switch run.GetConclusion() {
+		case "success", "neutral", "skipped":
+			states = append(states, ciPassing)
+		default: // failure, cancelled, timed_out, action_required
+			states = append(states, ciFailing)
+		}
+	}
+
+	if len(states) == 0 {
+		return ""
+	}
+
+	result := ciPassing
+	for _, state := range states {
+		switch state {
+		case "failure", "error", ciFailing:
+			return ciFailing
+		case ciPending:
+			result = ciPending
+		}
+	}
+	return result
+}
+
+// Only each reviewer's latest approving or blocking review counts.
+func reviewDecision(reviews []*github.PullRequestReview) (approvals int, changesRequested bool) {
+	latest := map[string]string{}
+	for _, review := range reviews {
+		switch state := review.GetState(); state {
+		case "APPROVED", "CHANGES_REQUESTED", "DISMISSED":
+			latest[review.User.GetLogin()] = state
+		}
+	}
+
+	for _, state := range latest {
+		switch state {
+		case "APPROVED":
+			approvals++
+		case "CHANGES_REQUESTED":
+			changesRequested = true
+		}
+	}
+	return approvals, changesRequested
+}
+
+func fetchPullRequestStatus(ctx context.Context, client *github.Client, status *pullRequestStatus) {
+	parts := strings.SplitN(status.repo, "/", 2)
+	if len(parts) != 2 {
+		return
+	}
+	owner, repo, number := parts[0], parts[1], status.pr.GetNumber()
+
+	pr, _, err := client.PullRequests.Get(ctx, owner, repo, number)
+	if err != nil {
+		debugf(ctx, "Failed to get pull request %s#%d: %v", status.repo, number, err)
+		return
+	}
+	status.mergeable = pr.Mergeable
+
+	combined, _, err := client.Repositories.GetCombinedStatus(ctx, owner, repo, pr.Head.GetSHA(), nil)
+	if err != nil {
+		debugf(ctx, "Failed to get status for %s#%d: %v", status.repo, number, err)
+	}
+	checks, _, err := client.Checks.ListCheckRunsForRef(ctx, owner, repo, pr.Head.GetSHA(), nil)
+	if err != nil {
+		debugf(ctx, "Failed to get check runs for %s#%d: %v", status.repo, number, err)
+		checks = &github.ListCheckRunsResults{}
+	}
+	status.ci = ciState(combined, checks.CheckRuns)
+
+	reviews, _, err := client.PullRequests.ListReviews(ctx, owner, repo, number, nil)
+	if err != nil {
+		debugf(ctx, "Failed to get reviews for %s#%d: %v", status.repo, number, err)
+	}
+	status.approvals, status.changesRequested = reviewDecision(reviews)
+}
+
+func getMyPullRequests(ctx context.Context, accessToken string) (FulfillmentBuilder, error) {
+	client := createGithubClient(ctx, accessToken)
+	opt := &github.SearchOptions{Sort: "updated", ListOptions: github.ListOptions{PerPage: myPullRequestsMax}}
+	result, _, err := client.Search.Issues(ctx, myPullRequestsQuery, opt)
+
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make(GithubPullRequestStatuses, len(result.Issues))
+	var wg sync.WaitGroup
+	wg.Add(len(result.Issues))
+	for i, pr := range result.Issues {
+		statuses[i] = &pullRequestStatus{pr: pr, repo: repoFullNameFromURL(pr.GetRepositoryURL())}
+		go func(status *pullRequestStatus) {
+			defer wg.Done()
+			fetchPullRequestStatus(ctx, client, status)
+		}(statuses[i])
+	}
+	wg.Wait()
+
+	return &statuses, nil
+}
+
+// Describe where a pull request stands, like "2 approvals, passing checks and merge conflicts"
+func (status *pullRequestStatus) describe() string {
+	var parts []string
+	switch {
+	case status.changesRequested:
+		parts = append(parts, "changes requested")
+	case status.approvals == 1:
+		parts = append(parts, "1 approval")
+	case status.approvals > 1:
+		parts = append(parts, fmt.Sprintf("%d approvals", status.approvals))
+	default:
+		parts = append(parts, "no approvals yet")
+	}
+
+	switch status.ci {
+	case ciPassing:
+		parts = append(parts, "passing checks")
+	case ciFailing:
+		parts = append(parts, "failing CI")
+	case ciPending:
+		parts = append(parts, "checks still running")
+	}
+
+	if status.mergeable != nil && !*status.mergeable {
+		parts = append(parts, "merge conflicts")
+	}
+	return joinWords(parts)
+}
+
+func (statuses *GithubPullRequestStatuses) toList(ctx context.Context) *PagedList {
+	list := newPagedList(ctx, MyPullRequestsIntent)
+	list.Empty = "You have no open pull requests."
+	if len(*statuses) == 1 {
+		list.SpeechHeader = "<p>You have 1 open pull request:</p>"
+	} else {
+		list.SpeechHeader = fmt.Sprintf("<p>You have %d open pull requests:</p>", len(*statuses))
+	}
+
+	for _, status := range *statuses {
+		repoName := status.repo[strings.LastIndex(status.repo, "/")+1:]
+		list.add(ListItem{
+			Text:   fmt.Sprintf("#%d in %s: %s. It has %s.\n%s", status.pr.GetNumber(), status.repo, status.pr.GetTitle(), status.describe(), status.pr.GetHTMLURL()),
+			Speech: fmt.Sprintf("<p>#%d in %s, %s, has %s.</p>", status.pr.GetNumber(), repoName, status.pr.GetTitle(), status.describe()),
+			Ref:    issueRef(status.repo, status.pr.GetNumber()),
+		})
+	}
+	return list
+}
+
+func (statuses *GithubPullRequestStatuses) buildFulfillment(ctx context.Context) *FulfillmentResp {
+	return statuses.toList(ctx).buildFulfillment(ctx)
+}
+
+func (statuses *GithubPullRequestStatuses) sessionState(ctx context.Context) *SessionState {
+	return statuses.toList(ctx).sessionState(ctx)
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"io/ioutil"
+	"testing"
+
+	"github.com/google/go-github/github"
+)
+
+func Test_repoFullNameFromURL(t *testing.T) {
+	tests := []struct {
+		name string
+		url  string
+		want string
+	}{
+		{"API URL", "https://api.github.com/repos/ephraimkunz/DailyGithub", "ephraimkunz/DailyGithub"},
+		{"Not an API URL", "ephraimkunz/DailyGithub", "ephraimkunz/DailyGithub"},
+		{"Empty", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := repoFullNameFromURL(tt.url); got != tt.want {
+				t.Errorf("repoFullNameFromURL() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_ciState(t *testing.T) {
+	completed := func(conclusion string) *github.CheckRun {
+		return &github.CheckRun{Status: github.String("completed"), Conclusion: github.String(conclusion)}
+	}
+	combined := func(state string, count int) *github.CombinedStatus {
+		return &github.CombinedStatus{State: github.String(state), TotalCount: github.Int(count)}
+	}
+	tests := []struct {
+		name   string
+		status *github.CombinedStatus
+		runs   []*github.CheckRun
+		want   string
+	}{
+		{"No CI", nil, nil, ""},
+		{"Empty combined status is ignored", combined("pending", 0), nil, ""},
+		{"Passing status", combined("success", 2), nil, ciPassing},
+		{"Failing status", combined("failure", 2), []*github.CheckRun{completed("success")}, ciFailing},
+		{"Passing checks", nil, []*github.CheckRun{completed("success"), completed("neutral")}, ciPassing},
+		{"Failing check", nil, []*github.CheckRun{completed("success"), completed("timed_out")}, ciFailing},
+		{"Running check", combined("success", 1), []*github.CheckRun{{Status: github.String("in_progress")}}, ciPending},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ciState(tt.status, tt.runs); got != tt.want {
+				t.Errorf("ciState() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_reviewDecision(t *testing.T) {
+	review := func(login, state string) *github.PullRequestReview {
+		return &github.PullRequestReview{User: &github.User{Login: github.String(login)}, State: github.String(state)}
+	}
+	tests := []struct {
+		name             string
+		reviews          []*github.PullRequestReview
+		approvals        int
+		changesRequested bool
+	}{
+		{"No reviews", nil, 0, false},
+		{"Two approvals", []*github.PullRequestReview{review("a", "APPROVED"), review("b", "APPROVED")}, 2, false},
+		{"Comments don't count", []*github.PullRequestReview{review("a", "APPROVED"), review("a", "COMMENTED")}, 1, false},
+		{"Later approval replaces changes requested", []*github.PullRequestReview{review("a", "CHANGES_REQUESTED"), review("a", "APPROVED")}, 1, false},
+		{"Changes requested", []*github.PullRequestReview{review("a", "APPROVED"), review("b", "CHANGES_REQUESTED")}, 1, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			approvals, changesRequested := reviewDecision(tt.reviews)
+			if approvals != tt.approvals || changesRequested != tt.changesRequested {
+				t.Errorf("reviewDecision() = %v, %v, want %v, %v", approvals, changesRequested, tt.approvals, tt.changesRequested)
+			}
+		})
+	}
+}
+
+func Test_GithubReviewRequests_header(t *testing.T) {
+	prs := func(n int) []github.Issue {
+		issues := make([]github.Issue, n)
+		for i := range issues {
+			issues[i] = github.Issue{Number: github.Int(i + 1), Title: github.String("Fix it"), User: &github.User{}}
+		}
+		return issues
+	}
+	tests := []struct {
+		name    string
+		reviews GithubReviewRequests
+		want    string
+	}{
+		{"One", GithubReviewRequests{prs(1), 1}, "<p>1 pull request is waiting on your review:</p>"},
+		{"All on one page", GithubReviewRequests{prs(3), 3}, "<p>3 pull requests are waiting on your review:</p>"},
+		{"More than a page", GithubReviewRequests{prs(30), 42}, "<p>42 pull requests are waiting on your review. Here are the 30 that have waited longest:</p>"},
+	}
+	ctx := withCLI(context.Background(), &cliOptions{ioutil.Discard, &Preferences{}})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.reviews.toList(ctx).SpeechHeader; got != tt.want {
+				t.Errorf("GithubReviewRequests.toList() header = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
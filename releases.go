@@ -0,0 +1,239 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/github"
+	"google.golang.org/appengine/datastore"
+)
+
+const (
+	ReleasesIntent = "releases_intent"
+
+	releaseCheckKind   = "ReleaseCheck" // Use to store in Cloud Datastore
+	maxReleaseRepos    = 60             // Most recently pushed repos to check, to bound API calls
+	releasesPerRepo    = 5
+	releaseFetchers    = 10 // Repos checked at once
+	releaseNotesLength = 150
+	firstReleaseWindow = 24 * time.Hour // How far back to look on the first check
+)
+
+// When the user last heard about new releases. Repos that couldn't be checked keep the time
+// they were last checked, so a failure doesn't lose their releases.
+type ReleaseCheck struct {
+	CheckedAt   time.Time
+	Missed      []string  // Full names of repos that failed last time
+	MissedSince time.Time // When the missed repos were last checked
+}
+
+type repoRelease struct {
+	repo    string // Full name
+	release *github.RepositoryRelease
+}
+
+type GithubReleases struct {
+	releases []repoRelease
+	since    time.Time
+	checked  bool     // Whether since is the last check, rather than the first check window
+	failed   []string // Repos that couldn't be checked this time
+}
+
+// Fetches a repo's latest releases, newest first
+type releaseLister func(ctx context.Context, repo *github.Repository) ([]*github.RepositoryRelease, error)
+
+// Returns the last check, or one from a day ago and false if there wasn't one or nowhere to
+// keep it.
+func loadReleaseCheck(ctx context.Context, userID string) (*ReleaseCheck, bool) {
+	first := &ReleaseCheck{CheckedAt: time.Now().Add(-firstReleaseWindow)}
+	if cliFromContext(ctx) != nil || userID == "" {
+		return first, false
+	}
+
+	check := &ReleaseCheck{}
+	key := datastore.NewKey(ctx, releaseCheckKind, userID, 0, nil)
+	if err := datastore.Get(ctx, key, check); err != nil {
+		if err != datastore.ErrNoSuchEntity {
+			debugf(ctx, "Failed to load release check for %s: %v", userID, err)
+		}
+		return first, false
+	}
+	return check, true
+}
+
+func saveReleaseCheck(ctx context.Context, userID string, check *ReleaseCheck) {
+	if cliFromContext(ctx) != nil || userID == "" {
+		return
+	}
+
+	key := datastore.NewKey(ctx, releaseCheckKind, userID, 0, nil)
+	if _, err := datastore.Put(ctx, key, check); err != nil {
+		debugf(ctx, "Failed to save release check for %s: %v", userID, err)
+	}
+}
+
+// When repo was last checked.
+func (check *ReleaseCheck) sinceFor(repo string) time.Time {
+	if containsString(check.Missed, repo) && check.MissedSince.Before(check.CheckedAt) {
+		return check.MissedSince
+	}
+	return check.CheckedAt
+}
+
+// The check to save after checking at checkedAt, when the repos in failed couldn't be read.
+func (check *ReleaseCheck) next(checkedAt time.Time, failed []string) *ReleaseCheck {
+	next := &ReleaseCheck{CheckedAt: checkedAt}
+	for _, repo := range failed {
+		if since := check.sinceFor(repo); next.MissedSince.IsZero() || since.Before(next.MissedSince) {
+			next.MissedSince = since
+		}
+		next.Missed = append(next.Missed, repo)
+	}
+	return next
+}
+
+// The starred and watched repos, most recently pushed first and without duplicates.
+func releaseRepos(ctx context.Context, client *github.Client) ([]*github.Repository, error) {
+	starOpt := &github.ActivityListStarredOptions{Sort: "updated", ListOptions: github.ListOptions{PerPage: maxReleaseRepos}}
+	starred, _, err := client.Activity.ListStarred(ctx, "", starOpt)
+	if err != nil {
+		return nil, err
+	}
+
+	watched, _, err := client.Activity.ListWatched(ctx, "", &github.ListOptions{PerPage: maxReleaseRepos})
+	if err != nil {
+		return nil, err
+	}
+
+	seen := map[string]bool{}
+	var repos []*github.Repository
+	for _, star := range starred {
+		watched = append(watched, star.Repository)
+	}
+	for _, repo := range watched {
+		if !seen[repo.GetFullName()] {
+			seen[repo.GetFullName()] = true
+			repos = append(repos, repo)
+		}
+	}
+
+	sort.SliceStable(repos, func(i, j int) bool {
+		return repos[i].GetPushedAt().After(repos[j].GetPushedAt().Time)
+	})
+	return repos[:minInt(len(repos), maxReleaseRepos)], nil
+}
+
+// Published releases newer than since, newest first. Releases come back newest first too.
+func newReleases(releases []*github.RepositoryRelease, since time.Time) []*github.RepositoryRelease {
+	var fresh []*github.RepositoryRelease
+	for _, release := range releases {
+		if release.GetDraft() {
+			continue
+		}
+		if !release.GetPublishedAt().After(since) {
+			break
+		}
+		fresh = append(fresh, release)
+	}
+	return fresh
+}
+
+func getReleases(ctx context.Context, accessToken, userID string) (FulfillmentBuilder, error) {
+	checkedAt := time.Now()
+	check, checked := loadReleaseCheck(ctx, userID)
+
+	client := createGithubClient(ctx, accessToken)
+	repos, err := releaseRepos(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+
+	list := func(ctx context.Context, repo *github.Repository) ([]*github.RepositoryRelease, error) {
+		opt := &github.ListOptions{PerPage: releasesPerRepo}
+		releases, _, err := client.Repositories.ListReleases(ctx, repo.Owner.GetLogin(), repo.GetName(), opt)
+		return releases, err
+	}
+	found, failed := checkReleases(ctx, repos, check, list)
+
+	saveReleaseCheck(ctx, userID, check.next(checkedAt, failed))
+	return &GithubReleases{found, check.CheckedAt, checked, failed}, nil
+}
+
+// Find each repo's releases since it was last checked, newest first, and the repos that
+// couldn't be read.
+func checkReleases(ctx context.Context, repos []*github.Repository, check *ReleaseCheck, list releaseLister) (found []repoRelease, failed []string) {
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	limit := make(chan struct{}, releaseFetchers)
+	for _, repo := range repos {
+		wg.Add(1)
+		go func(repo *github.Repository) {
+			defer wg.Done()
+			limit <- struct{}{}
+			defer func() { <-limit }()
+
+			releases, err := list(ctx, repo)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				debugf(ctx, "Failed to list releases for %s: %v", repo.GetFullName(), err)
+				failed = append(failed, repo.GetFullName())
+				return
+			}
+			for _, release := range newReleases(releases, check.sinceFor(repo.GetFullName())) {
+				found = append(found, repoRelease{repo.GetFullName(), release})
+			}
+		}(repo)
+	}
+	wg.Wait()
+
+	sort.SliceStable(found, func(i, j int) bool {
+		return found[i].release.GetPublishedAt().After(found[j].release.GetPublishedAt().Time)
+	})
+	sort.Strings(failed) // Goroutines finish in any order
+	return found, failed
+}
+
+func (rel *GithubReleases) toList(ctx context.Context) *PagedList {
+	since := sinceWhen(rel.since, time.Now(), preferencesFromContext(ctx).location())
+	if rel.checked && (since == "earlier today" || since == "yesterday") {
+		since = "you last checked " + since
+	}
+
+	list := newPagedList(ctx, ReleasesIntent)
+	list.SpeechHeader = fmt.Sprintf("<p>Here are the new releases since %s:</p>", since)
+	list.Empty = fmt.Sprintf("There are no new releases in your starred or watched repos since %s.", since)
+	if n := len(rel.failed); n > 0 {
+		unchecked := fmt.Sprintf("I couldn't check %s this time, so I'll look at them again next time.", pluralize(n, "repo", "repos"))
+		if n == 1 {
+			unchecked = fmt.Sprintf("I couldn't check %s this time, so I'll look at it again next time.", rel.failed[0])
+		}
+		list.SpeechHeader += "<p>" + unchecked + "</p>"
+		list.TextHeader = unchecked
+		list.Empty += " " + unchecked
+	}
+
+	for i, found := range rel.releases {
+		description := fmt.Sprintf("%s released %s", found.repo, found.release.GetTagName())
+		if notes := truncateWords(firstLine(found.release.GetBody()), releaseNotesLength); notes != "" {
+			description += ": " + notes
+		}
+		list.add(ListItem{
+			Text:   fmt.Sprintf("#%d: %s\n%s", i+1, description, found.release.GetHTMLURL()),
+			Speech: fmt.Sprintf("<p>#%d: %s</p>", i+1, description),
+			Ref:    found.repo,
+		})
+	}
+	return list
+}
+
+func (rel *GithubReleases) buildFulfillment(ctx context.Context) *FulfillmentResp {
+	return rel.toList(ctx).buildFulfillment(ctx)
+}
+
+func (rel *GithubReleases) sessionState(ctx context.Context) *SessionState {
+	return rel.toList(ctx).sessionState(ctx)
+}
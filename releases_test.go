@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/github"
+)
+
+func Test_newReleases(t *testing.T) {
+	since := time.Date(2018, 3, 10, 12, 0, 0, 0, time.UTC)
+	release := func(tag string, published time.Time, draft bool) *github.RepositoryRelease {
+		return &github.RepositoryRelease{TagName: github.String(tag), PublishedAt: &github.Timestamp{Time: published}, Draft: github.Bool(draft)}
+	}
+	releases := []*github.RepositoryRelease{
+		release("v3", since.Add(2*time.Hour), false),
+		release("v3-draft", time.Time{}, true),
+		release("v2", since.Add(time.Hour), false),
+		release("v1", since, false),
+		release("v0", since.Add(-time.Hour), false),
+	}
+
+	var tags []string
+	for _, r := range newReleases(releases, since) {
+		tags = append(tags, r.GetTagName())
+	}
+	if len(tags) != 2 || tags[0] != "v3" || tags[1] != "v2" {
+		t.Errorf("newReleases() = %v, want [v3 v2]", tags)
+	}
+}
+
+func Test_ReleaseCheck_next(t *testing.T) {
+	lastWeek := time.Date(2018, 3, 3, 12, 0, 0, 0, time.UTC)
+	yesterday := time.Date(2018, 3, 9, 12, 0, 0, 0, time.UTC)
+	now := time.Date(2018, 3, 10, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		check  ReleaseCheck
+		failed []string
+		want   ReleaseCheck
+	}{
+		{"All checked", ReleaseCheck{CheckedAt: yesterday}, nil, ReleaseCheck{CheckedAt: now}},
+		{"One failed", ReleaseCheck{CheckedAt: yesterday}, []string{"golang/go"},
+			ReleaseCheck{CheckedAt: now, Missed: []string{"golang/go"}, MissedSince: yesterday}},
+		{"Failed again", ReleaseCheck{CheckedAt: yesterday, Missed: []string{"golang/go"}, MissedSince: lastWeek}, []string{"golang/go"},
+			ReleaseCheck{CheckedAt: now, Missed: []string{"golang/go"}, MissedSince: lastWeek}},
+		{"Caught up", ReleaseCheck{CheckedAt: yesterday, Missed: []string{"golang/go"}, MissedSince: lastWeek}, nil,
+			ReleaseCheck{CheckedAt: now}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.check.next(now, tt.failed); !reflect.DeepEqual(*got, tt.want) {
+				t.Errorf("ReleaseCheck.next() = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_checkReleases(t *testing.T) {
+	lastWeek := time.Date(2018, 3, 3, 12, 0, 0, 0, time.UTC)
+	yesterday := time.Date(2018, 3, 9, 12, 0, 0, 0, time.UTC)
+	release := func(tag string, published time.Time) *github.RepositoryRelease {
+		return &github.RepositoryRelease{TagName: github.String(tag), PublishedAt: &github.Timestamp{Time: published}}
+	}
+	available := map[string][]*github.RepositoryRelease{
+		"golang/go":   {release("go1.10.1", yesterday.Add(time.Hour)), release("go1.10", lastWeek.Add(time.Hour))},
+		"rust/rust":   {release("1.24.1", yesterday.Add(-time.Hour))},
+		"kube/kube":   nil,
+		"broken/repo": nil,
+	}
+	list := func(ctx context.Context, repo *github.Repository) ([]*github.RepositoryRelease, error) {
+		if repo.GetFullName() == "broken/repo" {
+			return nil, errors.New("rate limited")
+		}
+		return available[repo.GetFullName()], nil
+	}
+	var repos []*github.Repository
+	for _, name := range []string{"golang/go", "rust/rust", "kube/kube", "broken/repo"} {
+		repos = append(repos, &github.Repository{FullName: github.String(name)})
+	}
+
+	// golang/go failed last week, so it's checked from then while the rest go from yesterday
+	check := &ReleaseCheck{CheckedAt: yesterday, Missed: []string{"golang/go"}, MissedSince: lastWeek}
+	ctx := withCLI(context.Background(), &cliOptions{ioutil.Discard, nil})
+	found, failed := checkReleases(ctx, repos, check, list)
+
+	var tags []string
+	for _, f := range found {
+		tags = append(tags, f.repo+" "+f.release.GetTagName())
+	}
+	if want := []string{"golang/go go1.10.1", "golang/go go1.10"}; !reflect.DeepEqual(tags, want) {
+		t.Errorf("checkReleases() found = %v, want %v", tags, want)
+	}
+	if want := []string{"broken/repo"}; !reflect.DeepEqual(failed, want) {
+		t.Errorf("checkReleases() failed = %v, want %v", failed, want)
+	}
+}
+
+func Test_GithubReleases_buildFulfillment(t *testing.T) {
+	ctx := withCLI(context.Background(), &cliOptions{ioutil.Discard, &Preferences{}})
+	rel := &GithubReleases{since: time.Now().Add(-time.Hour), checked: true, failed: []string{"broken/repo"}}
+	want := " I couldn't check broken/repo this time, so I'll look at it again next time."
+	if got := rel.buildFulfillment(ctx).DisplayText; !strings.HasSuffix(got, want) {
+		t.Errorf("GithubReleases.buildFulfillment() = %v, want %v", got, want)
+	}
+}
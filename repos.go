@@ -0,0 +1,196 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/github"
+)
+
+const (
+	RepoReportIntent = "repo_report_intent"
+
+	reportWindow   = 14 * 24 * time.Hour // Matches the traffic API, which only covers two weeks
+	maxReportRepos = 100                 // Owned repos to consider when picking the top ones
+)
+
+// How one of the user's repos has been doing over the last two weeks
+type repoReport struct {
+	repo        *github.Repository
+	starsGained int  // -1 if unknown
+	views       *int // Nil when the token can't see traffic
+	clones      *int
+}
+
+type GithubRepoReports []*repoReport
+
+// Compare repo names the way they're spoken, so "daily github" matches "DailyGithub".
+func normalizeName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '-', '_', '.':
+			return -1
+		}
+		return r
+	}, strings.ToLower(name))
+}
+
+// Pick the repos to report on: the one named, or the most starred.
+func pickReportRepos(repos []*github.Repository, name string, count int) []*github.Repository {
+	if name != "" {
+		for _, repo := range repos {
+			if normalizeName(repo.GetName()) == normalizeName(name) {
+				return []*github.Repository{repo}
+			}
+		}
+		return nil
+	}
+
+	sorted := make([]*github.Repository, len(repos))
+	copy(sorted, repos)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].GetStargazersCount() > sorted[j].GetStargazersCount()
+	})
+	return sorted[:minInt(count, len(sorted))]
+}
+
+func fetchRepoReport(ctx context.Context, client *github.Client, report *repoReport) {
+	owner, name := report.repo.Owner.GetLogin(), report.repo.GetName()
+
+	stars, err := starsSince(ctx, client, owner, name, time.Now().Add(-reportWindow))
+	if err != nil {
+		debugf(ctx, "Failed to count new stars for %s: %v", report.repo.GetFullName(), err)
+		stars = -1
+	}
+	report.starsGained = stars
+
+	// Traffic needs push access, so a 403 here just means we leave it out
+	views, _, err := client.Repositories.ListTrafficViews(ctx, owner, name, nil)
+	if err == nil {
+		report.views = views.Count
+	} else if !hasStatus(err, http.StatusForbidden) {
+		debugf(ctx, "Failed to get views for %s: %v", report.repo.GetFullName(), err)
+	}
+
+	clones, _, err := client.Repositories.ListTrafficClones(ctx, owner, name, nil)
+	if err == nil {
+		report.clones = clones.Count
+	} else if !hasStatus(err, http.StatusForbidden) {
+		debugf(ctx, "Failed to get clones for %s: %v", report.repo.GetFullName(), err)
+	}
+}
+
+func hasStatus(err error, code int) bool {
+	if errResp, ok := err.(*github.ErrorResponse); ok && errResp.Response != nil {
+		return errResp.Response.StatusCode == code
+	}
+	return false
+}
+
+func getRepoReport(ctx context.Context, accessToken string, repoName string) (FulfillmentBuilder, error) {
+	client := createGithubClient(ctx, accessToken)
+	opt := &github.RepositoryListOptions{Type: "owner", ListOptions: github.ListOptions{PerPage: maxReportRepos}}
+	repos, _, err := client.Repositories.List(ctx, "", opt)
+	if err != nil {
+		return nil, err
+	}
+
+	picked := pickReportRepos(repos, repoName, preferencesFromContext(ctx).pageSize())
+	if len(picked) == 0 && repoName != "" {
+		reply := fmt.Sprintf("I couldn't find a repo of yours called %s.", repoName)
+		return &SpokenResponse{reply, reply, nil}, nil
+	}
+
+	reports := make(GithubRepoReports, len(picked))
+	var wg sync.WaitGroup
+	wg.Add(len(picked))
+	for i, repo := range picked {
+		reports[i] = &repoReport{repo: repo}
+		go func(report *repoReport) {
+			defer wg.Done()
+			fetchRepoReport(ctx, client, report)
+		}(reports[i])
+	}
+	wg.Wait()
+
+	return &reports, nil
+}
+
+// Describe a repo's numbers, like "120 stars, 12 new in the last two weeks, 4 forks and 3 open issues"
+func (report *repoReport) describe() string {
+	stars := pluralize(report.repo.GetStargazersCount(), "star", "stars")
+	if report.starsGained > 0 {
+		stars += fmt.Sprintf(", %d new in the last two weeks", report.starsGained)
+	}
+
+	parts := []string{
+		stars,
+		pluralize(report.repo.GetForksCount(), "fork", "forks"),
+		pluralize(report.repo.GetOpenIssuesCount(), "open issue", "open issues"),
+	}
+	if report.views != nil {
+		parts = append(parts, pluralize(*report.views, "view", "views"))
+	}
+	if report.clones != nil {
+		parts = append(parts, pluralize(*report.clones, "clone", "clones"))
+	}
+	return joinWords(parts)
+}
+
+func (reports *GithubRepoReports) toList(ctx context.Context) *PagedList {
+	list := newPagedList(ctx, RepoReportIntent)
+	list.Empty = "You don't own any repos yet."
+	list.SpeechHeader = "<p>Here's how your repos are doing:</p>"
+	if len(*reports) == 1 {
+		list.SpeechHeader = ""
+	}
+
+	for i, report := range *reports {
+		list.add(ListItem{
+			Text:   fmt.Sprintf("#%d. %s: %s\n%s", i+1, report.repo.GetFullName(), report.describe(), report.repo.GetHTMLURL()),
+			Speech: fmt.Sprintf("<p>#%d. %s has %s.</p>", i+1, report.repo.GetName(), report.describe()),
+			Ref:    report.repo.GetFullName(),
+		})
+	}
+	return list
+}
+
+func (reports *GithubRepoReports) buildFulfillment(ctx context.Context) *FulfillmentResp {
+	return reports.toList(ctx).buildFulfillment(ctx)
+}
+
+func (reports *GithubRepoReports) sessionState(ctx context.Context) *SessionState {
+	return reports.toList(ctx).sessionState(ctx)
+}
+
+// Find the full name of a repo the user spoke, either "owner/repo" or just the name of one of
+// their own or a collaborator's repos. Returns an empty name if there's no such repo.
+func resolveRepo(ctx context.Context, client *github.Client, spoken string) (string, error) {
+	spoken = strings.TrimSpace(spoken)
+	if owner, name, ok := parseRepoRef(strings.Replace(spoken, " slash ", "/", 1)); ok {
+		repo, _, err := client.Repositories.Get(ctx, owner, name)
+		if hasStatus(err, http.StatusNotFound) {
+			return "", nil
+		} else if err != nil {
+			return "", err
+		}
+		return repo.GetFullName(), nil
+	}
+
+	opt := &github.RepositoryListOptions{Affiliation: "owner,collaborator,organization_member", ListOptions: github.ListOptions{PerPage: maxReportRepos}}
+	repos, _, err := client.Repositories.List(ctx, "", opt)
+	if err != nil {
+		return "", err
+	}
+	for _, repo := range repos {
+		if normalizeName(repo.GetName()) == normalizeName(spoken) {
+			return repo.GetFullName(), nil
+		}
+	}
+	return "", nil
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/google/go-github/github"
+)
+
+func Test_normalizeName(t *testing.T) {
+	tests := []struct {
+		name string
+		arg  string
+		want string
+	}{
+		{"Spoken", "daily github", "dailygithub"},
+		{"Hyphens", "Daily-Github", "dailygithub"},
+		{"Dots", "socket.io", "socketio"},
+		{"Underscores", "my_repo", "myrepo"},
+		{"Empty", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := normalizeName(tt.arg); got != tt.want {
+				t.Errorf("normalizeName() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_pickReportRepos(t *testing.T) {
+	repo := func(name string, stars int) *github.Repository {
+		return &github.Repository{Name: github.String(name), StargazersCount: github.Int(stars)}
+	}
+	a, b, c := repo("DailyGithub", 5), repo("dotfiles", 20), repo("blog", 1)
+	repos := []*github.Repository{a, b, c}
+
+	tests := []struct {
+		name  string
+		repo  string
+		count int
+		want  []*github.Repository
+	}{
+		{"MostStarred", "", 2, []*github.Repository{b, a}},
+		{"CountOverLength", "", 5, []*github.Repository{b, a, c}},
+		{"ByName", "daily github", 2, []*github.Repository{a}},
+		{"NoMatch", "nothing", 2, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := pickReportRepos(repos, tt.repo, tt.count); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("pickReportRepos() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+	if repos[0] != a {
+		t.Errorf("pickReportRepos() reordered its input")
+	}
+}
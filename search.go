@@ -0,0 +1,148 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/google/go-github/github"
+)
+
+const SearchReposIntent = "search_repos_intent"
+
+// What the user asked to search for, like "Go repos about rate limiting with over 1000 stars"
+type RepoSearch struct {
+	Topic    string
+	Lang     string // Search name, like "c#", empty for any language
+	LangName string // Spoken name, like "C#"
+	MinStars int    // 0 for no threshold
+}
+
+// Build a search from a trending language. Its URL name is escaped, and go-github escapes
+// the query again, so it's unescaped here or C# would search for "c%23".
+func newRepoSearch(topic, langName, urlName string, minStars int) RepoSearch {
+	lang, err := url.PathUnescape(urlName)
+	if err != nil {
+		lang = urlName
+	}
+	return RepoSearch{Topic: strings.TrimSpace(topic), Lang: lang, LangName: langName, MinStars: minStars}
+}
+
+type GithubSearchResults struct {
+	search RepoSearch
+	repos  []github.Repository
+	total  int
+}
+
+// Parse a spoken star count, like "1000", "1,000" or "2k".
+func parseStars(spoken string) (int, error) {
+	spoken = strings.ToLower(strings.Replace(strings.TrimSpace(spoken), ",", "", -1))
+	if spoken == "" {
+		return 0, nil
+	}
+
+	multiplier := 1
+	if strings.HasSuffix(spoken, "k") {
+		spoken, multiplier = strings.TrimSuffix(spoken, "k"), 1000
+	}
+	n, err := strconv.ParseFloat(strings.TrimSpace(spoken), 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("I didn't understand %s stars.", spoken)
+	}
+	return int(n * float64(multiplier)), nil
+}
+
+// The query in Github's search syntax, like "rate limiting language:go stars:>1000"
+func (search RepoSearch) query() string {
+	parts := []string{strings.TrimSpace(search.Topic)}
+	if search.Lang != "" {
+		parts = append(parts, "language:"+search.Lang)
+	}
+	if search.MinStars > 0 {
+		parts = append(parts, fmt.Sprintf("stars:>%d", search.MinStars))
+	}
+	return strings.TrimSpace(strings.Join(parts, " "))
+}
+
+// Describe the search as asked, like "Go repos about rate limiting with over 1000 stars"
+func (search RepoSearch) describe() string {
+	description := "repos"
+	if search.LangName != "" {
+		description = search.LangName + " repos"
+	}
+	if search.Topic != "" {
+		description += " about " + search.Topic
+	}
+	if search.MinStars > 0 {
+		description += fmt.Sprintf(" with over %d stars", search.MinStars)
+	}
+	return description
+}
+
+// Search public repos. Only a spoken language narrows the search, not the default one.
+func searchRepos(ctx context.Context, client *http.Client, req *IntentRequest) (FulfillmentBuilder, error) {
+	if strings.TrimSpace(req.Topic) == "" && req.Lang == "" {
+		reply := "What should I search for? You can say something like Go repos about rate limiting."
+		return &SpokenResponse{reply, reply, nil}, nil
+	}
+
+	minStars, err := parseStars(req.Stars)
+	if err != nil {
+		return &SpokenResponse{err.Error(), err.Error(), nil}, nil
+	}
+	langName, urlName := resolveTrendingLang(client, req.Lang)
+	search := newRepoSearch(req.Topic, langName, urlName, minStars)
+	if req.Lang != "" && search.Lang == "" {
+		reply := fmt.Sprintf("Sorry, I don't know the language %s.", req.Lang)
+		return &SpokenResponse{reply, reply, nil}, nil
+	}
+
+	count := defaultTrendingRepos
+	if i, err := strconv.Atoi(req.Number); err == nil && i > 0 {
+		count = i
+	} else if prefs := preferencesFromContext(ctx); prefs.ListLength > 0 {
+		count = prefs.ListLength
+	}
+
+	opt := &github.SearchOptions{Sort: "stars", Order: "desc", ListOptions: github.ListOptions{PerPage: minInt(count, 100)}}
+	result, _, err := createGithubClient(ctx, req.AccessToken).Search.Repositories(ctx, search.query(), opt)
+	if err != nil {
+		return nil, err
+	}
+	return &GithubSearchResults{search, result.Repositories, result.GetTotal()}, nil
+}
+
+func (results *GithubSearchResults) toList(ctx context.Context) *PagedList {
+	list := newPagedList(ctx, SearchReposIntent)
+	list.Empty = fmt.Sprintf("I couldn't find any %s.", results.search.describe())
+	list.SpeechHeader = fmt.Sprintf("<p>I found %d %s. Here are the most starred:</p>", results.total, results.search.describe())
+	if results.total == 1 {
+		list.SpeechHeader = "<p>I found one:</p>"
+	}
+
+	for i := range results.repos {
+		repo := &results.repos[i]
+		owner, name, description := repo.GetOwner().GetLogin(), repo.GetName(), repo.GetDescription()
+		item := ListItem{
+			Text:   fmt.Sprintf("#%d. %s by %s: %s", i+1, name, owner, description),
+			Speech: fmt.Sprintf("<p>#%d. %s by %s: %s</p>", i+1, name, owner, description),
+			Ref:    repo.GetFullName(),
+		}
+		if preferencesFromContext(ctx).brief() {
+			item.Speech = fmt.Sprintf("<p>#%d. %s by %s</p>", i+1, name, owner)
+		}
+		list.add(item)
+	}
+	return list
+}
+
+func (results *GithubSearchResults) buildFulfillment(ctx context.Context) *FulfillmentResp {
+	return results.toList(ctx).buildFulfillment(ctx)
+}
+
+func (results *GithubSearchResults) sessionState(ctx context.Context) *SessionState {
+	return results.toList(ctx).sessionState(ctx)
+}
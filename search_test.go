@@ -0,0 +1,55 @@
+package main
+
+import "testing"
+
+func Test_parseStars(t *testing.T) {
+	tests := []struct {
+		name    string
+		spoken  string
+		want    int
+		wantErr bool
+	}{
+		{"Empty", "", 0, false},
+		{"Plain", "1000", 1000, false},
+		{"Commas", "1,000", 1000, false},
+		{"Thousands", "2.5k", 2500, false},
+		{"Words", "lots", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseStars(tt.spoken)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parseStars() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("parseStars() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_RepoSearch(t *testing.T) {
+	tests := []struct {
+		name         string
+		search       RepoSearch
+		wantQuery    string
+		wantDescribe string
+	}{
+		{"Everything", newRepoSearch("rate limiting", "Go", "go", 1000), "rate limiting language:go stars:>1000", "Go repos about rate limiting with over 1000 stars"},
+		{"Topic", newRepoSearch(" static site generators ", "", "", 0), "static site generators", "repos about static site generators"},
+		{"Language", newRepoSearch("", "Rust", "rust", 50), "language:rust stars:>50", "Rust repos with over 50 stars"},
+		{"Escaped language", newRepoSearch("web frameworks", "C#", "c%23", 0), "web frameworks language:c#", "C# repos about web frameworks"},
+		{"Symbols", newRepoSearch("", "C++", "c++", 0), "language:c++", "C++ repos"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.search.query(); got != tt.wantQuery {
+				t.Errorf("RepoSearch.query() = %v, want %v", got, tt.wantQuery)
+			}
+			if got := tt.search.describe(); got != tt.wantDescribe {
+				t.Errorf("RepoSearch.describe() = %v, want %v", got, tt.wantDescribe)
+			}
+		})
+	}
+}
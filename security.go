@@ -0,0 +1,244 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/google/go-github/github"
+)
+
+const (
+	SecurityAlertsIntent = "security_alerts_intent"
+
+	alertsPerPage    = 100
+	alertFetchers    = 10 // Repos checked at once
+	maxCriticalRead  = 5  // Critical alerts read by name, the rest are just counted
+	severityCritical = "critical"
+)
+
+// The kinds of alert we total, and where the API keeps them under a repo
+var alertKinds = []struct {
+	name string
+	path string
+}{
+	{"Dependabot", "dependabot/alerts"},
+	{"code scanning", "code-scanning/alerts"},
+	{"secret scanning", "secret-scanning/alerts"},
+}
+
+// Severities from most to least severe, in the order they're read
+var severities = []string{severityCritical, "high", "medium", "low"}
+
+// go-github doesn't cover these APIs, so this decodes just what we read from all three kinds.
+type SecurityAlert struct {
+	// Dependabot
+	Dependency *struct {
+		Package struct {
+			Name string `json:"name"`
+		} `json:"package"`
+	} `json:"dependency"`
+	SecurityAdvisory *struct {
+		Severity string `json:"severity"`
+	} `json:"security_advisory"`
+
+	// Code scanning
+	Rule *struct {
+		Description           string `json:"description"`
+		SecuritySeverityLevel string `json:"security_severity_level"`
+	} `json:"rule"`
+
+	// Secret scanning
+	SecretTypeDisplayName string `json:"secret_type_display_name"`
+}
+
+// Severity as one of severities, or empty for secrets and alerts without one.
+func (alert *SecurityAlert) severity() string {
+	var severity string
+	switch {
+	case alert.SecurityAdvisory != nil:
+		severity = alert.SecurityAdvisory.Severity
+	case alert.Rule != nil:
+		severity = alert.Rule.SecuritySeverityLevel
+	}
+
+	if severity = strings.ToLower(severity); severity == "moderate" {
+		return "medium" // Older advisories use this
+	}
+	return severity
+}
+
+// What the alert is about, like a package name or a code scanning rule.
+func (alert *SecurityAlert) subject() string {
+	switch {
+	case alert.Dependency != nil:
+		return alert.Dependency.Package.Name
+	case alert.Rule != nil:
+		return alert.Rule.Description
+	default:
+		return alert.SecretTypeDisplayName
+	}
+}
+
+type SecurityReport struct {
+	repos    int
+	counts   map[string]int // By severity
+	secrets  int
+	critical []string       // Like "lodash in owner/repo"
+	denied   map[string]int // Repos that refused each alert kind with a 403
+}
+
+func (report *SecurityReport) add(repo string, alerts []*SecurityAlert) {
+	for _, alert := range alerts {
+		if alert.SecretTypeDisplayName != "" {
+			report.secrets++
+			continue
+		}
+
+		severity := alert.severity()
+		report.counts[severity]++
+		if severity == severityCritical {
+			report.critical = append(report.critical, fmt.Sprintf("%s in %s", alert.subject(), repo))
+		}
+	}
+}
+
+// Record one repo's alerts of a kind. A 403 can mean the token lacks the security_events
+// scope, but also that the kind is turned off for the repo, or needs Advanced Security there.
+// So like a 404 it only counts against the repo, and the kind is reported as unreadable when
+// every repo refused it.
+func (report *SecurityReport) record(repo, kind string, alerts []*SecurityAlert, err error) {
+	switch {
+	case hasStatus(err, http.StatusForbidden):
+		report.denied[kind]++
+	case err != nil:
+		// Not turned on for this repo, or already logged
+	default:
+		report.add(repo, alerts)
+	}
+}
+
+// Alert kinds no repo would let us read, in the order of alertKinds.
+func (report *SecurityReport) deniedKinds() []string {
+	var denied []string
+	for _, kind := range alertKinds {
+		if report.repos > 0 && report.denied[kind.name] == report.repos {
+			denied = append(denied, kind.name)
+		}
+	}
+	return denied
+}
+
+// The repos the user can administer, since only admins can see alerts.
+func adminRepos(ctx context.Context, client *github.Client) ([]*github.Repository, error) {
+	opt := &github.RepositoryListOptions{Affiliation: "owner,collaborator,organization_member", ListOptions: github.ListOptions{PerPage: maxReportRepos}}
+	repos, _, err := client.Repositories.List(ctx, "", opt)
+	if err != nil {
+		return nil, err
+	}
+
+	var admin []*github.Repository
+	for _, repo := range repos {
+		if repo.GetPermissions()["admin"] && !repo.GetArchived() {
+			admin = append(admin, repo)
+		}
+	}
+	return admin, nil
+}
+
+func getSecurityAlerts(ctx context.Context, accessToken string) (FulfillmentBuilder, error) {
+	client := createGithubClient(ctx, accessToken)
+	repos, err := adminRepos(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &SecurityReport{repos: len(repos), counts: map[string]int{}, denied: map[string]int{}}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	limit := make(chan struct{}, alertFetchers)
+	for _, repo := range repos {
+		for _, kind := range alertKinds {
+			wg.Add(1)
+			go func(repo, kind, path string) {
+				defer wg.Done()
+				limit <- struct{}{}
+				defer func() { <-limit }()
+
+				var alerts []*SecurityAlert
+				url := fmt.Sprintf("repos/%s/%s?state=open&per_page=%d", repo, path, alertsPerPage)
+				err := getAPIURL(ctx, client, url, &alerts)
+				if err != nil && !hasStatus(err, http.StatusForbidden) && !hasStatus(err, http.StatusNotFound) {
+					debugf(ctx, "Failed to get %s alerts for %s: %v", kind, repo, err)
+				}
+
+				mu.Lock()
+				defer mu.Unlock()
+				report.record(repo, kind, alerts, err)
+			}(repo.GetFullName(), kind.name, kind.path)
+		}
+	}
+	wg.Wait()
+
+	return report, nil
+}
+
+// Read the totals, the critical alerts by name, and which kinds couldn't be read.
+func (report *SecurityReport) describe() string {
+	denied := report.deniedKinds()
+	if len(denied) == len(alertKinds) {
+		return "I don't have permission to read your security alerts. Try linking your Github account again and allowing access to security events."
+	}
+
+	var totals []string
+	total := 0
+	for _, severity := range severities {
+		if n := report.counts[severity]; n > 0 {
+			totals = append(totals, fmt.Sprintf("%d %s", n, severity))
+			total += n
+		}
+	}
+	if unrated := report.counts[""]; unrated > 0 {
+		totals = append(totals, fmt.Sprintf("%d unrated", unrated))
+		total += unrated
+	}
+
+	var found []string
+	if total == 1 {
+		found = append(found, totals[0]+" open alert")
+	} else if total > 1 {
+		found = append(found, joinWords(totals)+" open alerts")
+	}
+	if report.secrets > 0 {
+		found = append(found, pluralize(report.secrets, "exposed secret", "exposed secrets"))
+	}
+
+	repos := pluralize(report.repos, "repo you administer", "repos you administer")
+	var summary string
+	if len(found) == 0 {
+		summary = fmt.Sprintf("There are no open security alerts in the %s.", repos)
+	} else {
+		summary = fmt.Sprintf("Across the %s, you have %s.", repos, strings.Join(found, " and "))
+	}
+
+	if n := len(report.critical); n > 0 {
+		critical := report.critical[:minInt(n, maxCriticalRead)]
+		if n > maxCriticalRead {
+			critical = append(critical, pluralize(n-maxCriticalRead, "more", "more"))
+		}
+		summary += " The critical ones are " + joinWords(critical) + "."
+	}
+
+	if len(denied) > 0 {
+		summary += fmt.Sprintf(" I couldn't read %s alerts, which needs the security events permission.", joinWords(denied))
+	}
+	return summary
+}
+
+func (report *SecurityReport) buildFulfillment(ctx context.Context) *FulfillmentResp {
+	summary := report.describe()
+	debugf(ctx, "Built fulfillment with string: %s", summary)
+	return &FulfillmentResp{"<speak>" + summary + "</speak>", summary}
+}
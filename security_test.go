@@ -0,0 +1,69 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/google/go-github/github"
+)
+
+func Test_SecurityReport_describe(t *testing.T) {
+	var alerts []*SecurityAlert
+	err := json.Unmarshal([]byte(`[
+		{"dependency": {"package": {"name": "lodash"}}, "security_advisory": {"severity": "critical"}},
+		{"dependency": {"package": {"name": "minimist"}}, "security_advisory": {"severity": "moderate"}},
+		{"rule": {"description": "SQL injection", "security_severity_level": "critical"}},
+		{"rule": {"description": "Unused variable"}},
+		{"secret_type_display_name": "GitHub Personal Access Token"}
+	]`), &alerts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	forbidden := &github.ErrorResponse{Response: &http.Response{StatusCode: http.StatusForbidden}}
+	notFound := &github.ErrorResponse{Response: &http.Response{StatusCode: http.StatusNotFound}}
+	type result struct {
+		repo   string
+		kind   string
+		alerts []*SecurityAlert
+		err    error
+	}
+	allKinds := func(repo string, err error) []result {
+		var results []result
+		for _, kind := range alertKinds {
+			results = append(results, result{repo, kind.name, nil, err})
+		}
+		return results
+	}
+
+	tests := []struct {
+		name    string
+		results []result
+		want    string
+	}{
+		{"None", nil, "There are no open security alerts in the 2 repos you administer."},
+		{"Alerts", []result{{"owner/app", "Dependabot", alerts, nil}},
+			"Across the 2 repos you administer, you have 2 critical, 1 medium and 1 unrated open alerts and 1 exposed secret. " +
+				"The critical ones are lodash in owner/app and SQL injection in owner/app."},
+		{"Not enabled", []result{{"owner/app", "code scanning", nil, notFound}},
+			"There are no open security alerts in the 2 repos you administer."},
+		{"One repo denied", append(allKinds("owner/lib", forbidden), result{"owner/app", "Dependabot", alerts[:1], nil}),
+			"Across the 2 repos you administer, you have 1 critical open alert. The critical ones are lodash in owner/app."},
+		{"Kind denied everywhere", []result{{"owner/app", "code scanning", nil, forbidden}, {"owner/lib", "code scanning", nil, forbidden}},
+			"There are no open security alerts in the 2 repos you administer. I couldn't read code scanning alerts, which needs the security events permission."},
+		{"All denied", append(allKinds("owner/app", forbidden), allKinds("owner/lib", forbidden)...),
+			"I don't have permission to read your security alerts. Try linking your Github account again and allowing access to security events."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := &SecurityReport{repos: 2, counts: map[string]int{}, denied: map[string]int{}}
+			for _, r := range tt.results {
+				report.record(r.repo, r.kind, r.alerts, r.err)
+			}
+			if got := report.describe(); got != tt.want {
+				t.Errorf("SecurityReport.describe() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+const (
+	// Alexa's built in confirmation intents are mapped onto these
+	YesIntent = "yes_intent"
+	NoIntent  = "no_intent"
+
+	// Dialogflow context that carries SessionState between turns
+	sessionContextName     = "dailygithub_session"
+	sessionContextParam    = "state"
+	sessionContextLifespan = 5
+)
+
+// SessionState is carried between turns of a conversation. Alexa keeps it in sessionAttributes,
+// the Assistant in a Dialogflow context.
+type SessionState struct {
+	// The list just read out, for paging and follow ups like "mark number 2 as read"
+	List *PagedList `json:"list,omitempty"`
+	// When the notifications were read, so a bulk "mark as read" doesn't touch newer ones
+	NotificationsReadAt time.Time `json:"notificationsReadAt"`
+	// Whether the notifications read leave out some unread ones, so a bulk "mark as read" sticks to those read
+	NotificationsPartial bool `json:"notificationsPartial,omitempty"`
+	// Action waiting on the user to say yes or no
+	PendingAction string `json:"pendingAction,omitempty"`
+	// The issue or pull request last heard about or acted on, for "close it"
+	Focus string `json:"focus,omitempty"`
+	// Failed workflow runs for "re-run the failed jobs"
+	Rerun *RerunTarget `json:"rerun,omitempty"`
+	// The issue to create if the user says yes
+	Draft *IssueDraft `json:"draft,omitempty"`
+}
+
+// Builders that continue the conversation implement this. Returning nil ends the session.
+type SessionBuilder interface {
+	FulfillmentBuilder
+	sessionState(ctx context.Context) *SessionState
+}
+
+// Returns the state to carry to the next turn, or nil if the conversation is over.
+func nextSessionState(ctx context.Context, builder FulfillmentBuilder) *SessionState {
+	if sb, ok := builder.(SessionBuilder); ok {
+		return sb.sessionState(ctx)
+	}
+	return nil
+}
+
+// A plain spoken reply. With a non-nil state it's a question that keeps the session open
+// until the user answers.
+type SpokenResponse struct {
+	text, speech string
+	state        *SessionState
+}
+
+func (resp *SpokenResponse) buildFulfillment(ctx context.Context) *FulfillmentResp {
+	debugf(ctx, "Built fulfillment with string: %s", resp.speech)
+	return &FulfillmentResp{"<speak>" + resp.speech + "</speak>", resp.text}
+}
+
+func (resp *SpokenResponse) sessionState(ctx context.Context) *SessionState {
+	return resp.state
+}
+
+// Answer a yes or no for whatever action the last turn asked the user to confirm.
+func confirmPendingAction(ctx context.Context, req *IntentRequest, confirmed bool) (FulfillmentBuilder, error) {
+	if req.Session.PendingAction == "" {
+		// Nothing to confirm, so this is the answer to "want to hear more?"
+		if confirmed && req.Session.List != nil && req.Session.List.hasNextPage() {
+			return pageList(req.Session, 1), nil
+		} else if confirmed {
+			return &SpokenResponse{"There's nothing to confirm.", "There's nothing to confirm.", nil}, nil
+		}
+		return &SpokenResponse{"Okay.", "Okay.", nil}, nil
+	}
+
+	if !confirmed {
+		return &SpokenResponse{"Okay, I won't change anything.", "Okay, I won't change anything.", nil}, nil
+	}
+
+	switch req.Session.PendingAction {
+	case pendingMarkAllRead:
+		return markAllNotificationsRead(ctx, req.AccessToken, req.Session)
+	case pendingCreateIssue:
+		return createIssue(ctx, req.AccessToken, req.Session)
+	case pendingCloseIssue:
+		return closeIssue(ctx, req.AccessToken, req.Session)
+	case pendingRerun:
+		return rerunFailedJobs(ctx, req.AccessToken, req.Session)
+	default:
+		return nil, errUnknownIntent
+	}
+}
+
+// Dialogflow context parameters are flat, so the state is stored as a JSON string.
+func sessionFromContexts(contexts []ContextReq) *SessionState {
+	state := &SessionState{}
+	for _, c := range contexts {
+		if c.Name != sessionContextName {
+			continue
+		}
+
+		if encoded, ok := c.Parameters[sessionContextParam].(string); ok {
+			json.Unmarshal([]byte(encoded), state)
+		}
+	}
+	return state
+}
+
+func sessionToContext(state *SessionState) FulfillmentContext {
+	if state == nil {
+		return FulfillmentContext{Name: sessionContextName, Lifespan: 0} // Clears the context
+	}
+
+	encoded, _ := json.Marshal(state)
+	return FulfillmentContext{
+		Name:       sessionContextName,
+		Lifespan:   sessionContextLifespan,
+		Parameters: map[string]string{sessionContextParam: string(encoded)},
+	}
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"io/ioutil"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func Test_sessionContextRoundTrip(t *testing.T) {
+	readAt := time.Date(2018, time.March, 10, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name  string
+		state *SessionState
+	}{
+		{"Empty state", &SessionState{}},
+		{"Notifications", &SessionState{List: &PagedList{Kind: NotificationsIntent, Items: []ListItem{{"a", "<p>a</p>", "1"}}, Page: 1, PageSize: 5}, NotificationsReadAt: readAt}},
+		{"Pending action", &SessionState{PendingAction: pendingMarkAllRead}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := sessionToContext(tt.state)
+			in := ContextReq{Name: out.Name, Parameters: map[string]interface{}{}}
+			for k, v := range out.Parameters {
+				in.Parameters[k] = v
+			}
+
+			if got := sessionFromContexts([]ContextReq{in}); !reflect.DeepEqual(got, tt.state) {
+				t.Errorf("sessionFromContexts() = %+v, want %+v", got, tt.state)
+			}
+		})
+	}
+}
+
+func Test_sessionToContextClears(t *testing.T) {
+	if got := sessionToContext(nil); got.Lifespan != 0 || got.Name != sessionContextName {
+		t.Errorf("sessionToContext(nil) = %+v, want lifespan 0", got)
+	}
+}
+
+func Test_confirmPendingAction(t *testing.T) {
+	list := &PagedList{Kind: TrendingReposIntent, PageSize: 1, Items: []ListItem{{"a", "<p>a</p>", "a/a"}, {"b", "<p>b</p>", "b/b"}}}
+	draft := &IssueDraft{"golang/go", "Crash"}
+	tests := []struct {
+		name      string
+		session   *SessionState
+		confirmed bool
+		want      string
+		wantState bool
+	}{
+		{"No to creating an issue", &SessionState{PendingAction: pendingCreateIssue, Draft: draft}, false, "Okay, I won't change anything.", false},
+		{"No to closing", &SessionState{PendingAction: pendingCloseIssue, Focus: "golang/go#1"}, false, "Okay, I won't change anything.", false},
+		{"Yes to more", &SessionState{List: list}, true, "\nb", true},
+		{"Yes to nothing", &SessionState{}, true, "There's nothing to confirm.", false},
+		{"No to nothing", &SessionState{}, false, "Okay.", false},
+	}
+	ctx := withCLI(context.Background(), &cliOptions{ioutil.Discard, &Preferences{}})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			builder, err := confirmPendingAction(ctx, &IntentRequest{Session: tt.session}, tt.confirmed)
+			if err != nil {
+				t.Fatalf("confirmPendingAction() error = %v", err)
+			}
+			if got := builder.buildFulfillment(ctx).DisplayText; got != tt.want {
+				t.Errorf("confirmPendingAction() = %q, want %q", got, tt.want)
+			}
+			if got := nextSessionState(ctx, builder) != nil; got != tt.wantState {
+				t.Errorf("confirmPendingAction() keeps session = %v, want %v", got, tt.wantState)
+			}
+		})
+	}
+}
+
+func Test_confirmCreateIssueWithoutDraft(t *testing.T) {
+	ctx := withCLI(context.Background(), &cliOptions{ioutil.Discard, &Preferences{}})
+	if _, err := confirmPendingAction(ctx, &IntentRequest{Session: &SessionState{PendingAction: pendingCreateIssue}}, true); err == nil {
+		t.Error("confirmPendingAction() with no draft succeeded, want an error")
+	}
+}
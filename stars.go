@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/google/go-github/github"
+)
+
+const (
+	StarIntent   = "star_intent"
+	UnstarIntent = "unstar_intent"
+	WatchIntent  = "watch_intent"
+)
+
+// Past tense and plain verbs for each repo action
+var repoActions = map[string][2]string{
+	StarIntent:   {"star", "Starred"},
+	UnstarIntent: {"unstar", "Unstarred"},
+	WatchIntent:  {"watch", "You're now watching"},
+}
+
+// The repo behind an item from the list just read. Issues and pull requests count as their repo.
+func repoFromItem(item *ListItem) (string, bool) {
+	ref := strings.SplitN(item.Ref, "#", 2)[0]
+	if _, _, ok := parseRepoRef(ref); !ok {
+		return "", false
+	}
+	return ref, true
+}
+
+// Star, unstar or watch a repo named by number from the list, or by its spoken name.
+func repoAction(ctx context.Context, req *IntentRequest) (FulfillmentBuilder, error) {
+	verbs, ok := repoActions[req.Name]
+	if !ok {
+		return nil, errUnknownIntent
+	}
+
+	client := createGithubClient(ctx, req.AccessToken)
+	var ref string
+	if req.Repo != "" {
+		var err error
+		if ref, err = resolveRepo(ctx, client, req.Repo); err != nil {
+			return nil, err
+		} else if ref == "" {
+			reply := fmt.Sprintf("I couldn't find a repo called %s.", req.Repo)
+			return &SpokenResponse{reply, reply, req.Session}, nil
+		}
+	} else {
+		item, err := resolveOrdinal(req.Session, req.Number)
+		if err != nil {
+			return &SpokenResponse{err.Error(), err.Error(), req.Session}, nil
+		}
+		if ref, ok = repoFromItem(item); !ok {
+			reply := fmt.Sprintf("Number %s isn't a repo.", req.Number)
+			return &SpokenResponse{reply, reply, req.Session}, nil
+		}
+	}
+
+	owner, name, _ := parseRepoRef(ref)
+	var err error
+	switch req.Name {
+	case StarIntent:
+		_, err = client.Activity.Star(ctx, owner, name)
+	case UnstarIntent:
+		_, err = client.Activity.Unstar(ctx, owner, name)
+	case WatchIntent:
+		_, _, err = client.Activity.SetRepositorySubscription(ctx, owner, name, &github.Subscription{Subscribed: github.Bool(true)})
+	}
+
+	if hasStatus(err, http.StatusForbidden) || hasStatus(err, http.StatusNotFound) {
+		reply := fmt.Sprintf("Sorry, I couldn't %s %s. Your Github account may need to be linked again.", verbs[0], ref)
+		return &SpokenResponse{reply, reply, req.Session}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	state := *req.Session
+	state.PendingAction = ""
+	done := fmt.Sprintf("%s %s.", verbs[1], ref)
+	return &SpokenResponse{done, done, &state}, nil
+}
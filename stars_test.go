@@ -0,0 +1,25 @@
+package main
+
+import "testing"
+
+func Test_repoFromItem(t *testing.T) {
+	tests := []struct {
+		name   string
+		ref    string
+		want   string
+		wantOk bool
+	}{
+		{"Repo", "golang/go", "golang/go", true},
+		{"Issue", "golang/go#12", "golang/go", true},
+		{"Notification thread", "230400425", "", false},
+		{"Empty", "", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := repoFromItem(&ListItem{Ref: tt.ref})
+			if got != tt.want || ok != tt.wantOk {
+				t.Errorf("repoFromItem() = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+}
@@ -78,11 +78,19 @@ func get(ctx context.Context, key string) (TrendingProjects, error) {
 	return *projects, err
 }
 
+// Fetch trending projects straight from Github, skipping the cache.
+func fetchTrending(client *http.Client, lang string) (TrendingProjects, error) {
+	trend := trending.NewTrendingWithClient(client)
+	projects, err := trend.GetProjects(trending.TimeToday, lang)
+	return TrendingProjects{projects}, err
+}
+
 // Cache trending data in Cloud Datastore because Github's trending endpoint
 // is so slow that Google Assistant times out before receiving a response.
 func refreshTrendingCache(w http.ResponseWriter, r *http.Request) {
 	ctx := appengine.NewContext(r)
-	ctxWithDeadline, _ := context.WithTimeout(ctx, 1*time.Hour) // This call sometimes takes a while
+	ctxWithDeadline, cancel := context.WithTimeout(ctx, 1*time.Hour) // This call sometimes takes a while
+	defer cancel()
 	client := urlfetch.Client(ctxWithDeadline)
 	trend := trending.NewTrendingWithClient(client)
 	languages, err := trend.GetLanguages()